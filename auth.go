@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Sharktheone/mcp262/runner"
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TOKEN_TTL is the expiration reported for static tokens, they are checked again on every request.
+const TOKEN_TTL = time.Hour
+
+// loadTokens maps the static tokens from the config and the token file (one token per line,
+// empty lines and lines starting with # are ignored) to the name of their client,
+// empty for tokens that aren't restricted beyond the global policy.
+func loadTokens(config *runner.AuthConfig) (map[string]string, error) {
+	tokens := make(map[string]string)
+
+	add := func(token string, client string) error {
+		if _, exists := tokens[token]; exists {
+			return errors.New("duplicate auth token")
+		}
+
+		tokens[token] = client
+		return nil
+	}
+
+	for _, t := range config.Tokens {
+		if t = strings.TrimSpace(t); t != "" {
+			if err := add(t, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	names := make(map[string]bool, len(config.Clients))
+	for _, c := range config.Clients {
+		if c.Name == "" || c.Token == "" {
+			return nil, errors.New("auth clients need a name and a token")
+		}
+
+		if names[c.Name] {
+			return nil, errors.New("duplicate auth client " + c.Name)
+		}
+
+		names[c.Name] = true
+
+		if err := add(c.Token, c.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.TokenFile == "" {
+		return tokens, nil
+	}
+
+	f, err := os.Open(config.TokenFile)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	n := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if err := add(line, ""); err != nil {
+			return nil, err
+		}
+		n++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		return nil, errors.New("token file " + config.TokenFile + " contains no tokens")
+	}
+
+	return tokens, nil
+}
+
+// authHandler rejects requests without one of the tokens before they reach the MCP server.
+// The client of the token is passed on in the auth.TokenInfo of the request.
+func authHandler(tokens map[string]string, handler http.Handler) http.Handler {
+	verify := func(ctx context.Context, token string) (*auth.TokenInfo, error) {
+		for t, client := range tokens {
+			if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+				return &auth.TokenInfo{
+					Expiration: time.Now().Add(TOKEN_TTL),
+					Extra:      map[string]any{"client": client},
+				}, nil
+			}
+		}
+
+		return nil, auth.ErrInvalidToken
+	}
+
+	return auth.RequireBearerToken(verify, nil)(handler)
+}
+
+func tokenClient(info *auth.TokenInfo) string {
+	if info == nil {
+		return ""
+	}
+
+	client, _ := info.Extra["client"].(string)
+	return client
+}
+
+// clientMiddleware rejects requests made with the token of another client than the one the session was created with.
+func clientMiddleware(client string) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if extra := req.GetExtra(); extra != nil && extra.TokenInfo != nil && tokenClient(extra.TokenInfo) != client {
+				return nil, errors.New("token does not belong to the client of this session")
+			}
+
+			return next(ctx, method, req)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Sharktheone/mcp262/runner/ci"
+	"github.com/Sharktheone/mcp262/runner/export"
+	"github.com/Sharktheone/mcp262/runner/results"
+)
+
+func main() {
+	in := flag.String("in", results.RESULT_PATH, "Results file to export")
+	isCi := flag.Bool("ci", false, "Input is in the compact CI format ([{s,p}])")
+	format := flag.String("format", "junit", "Export format: junit, tap or csv")
+	out := flag.String("out", "", "Output file (defaults to stdout)")
+	dir := flag.String("dir", "", "Only export tests in this directory")
+
+	flag.Parse()
+
+	f, err := export.ParseFormat(*format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var res []results.Result
+	if *isCi {
+		tr, err := ci.LoadPrevCi(*in)
+		if err != nil {
+			log.Fatalf("Failed to load CI results %s: %v", *in, err)
+		}
+		if tr != nil {
+			res = tr.TestResults
+		}
+	} else {
+		res, err = results.LoadResultsPath(*in)
+		if err != nil {
+			log.Fatalf("Failed to load results %s: %v", *in, err)
+		}
+	}
+
+	if res == nil {
+		log.Fatalf("No results found in %s", *in)
+	}
+
+	if *dir != "" {
+		prefix := strings.TrimSuffix(*dir, "/") + "/"
+		filtered := make([]results.Result, 0, len(res))
+		for _, r := range res {
+			if strings.HasPrefix(r.Path, prefix) {
+				filtered = append(filtered, r)
+			}
+		}
+		res = filtered
+	}
+
+	var w io.Writer = os.Stdout
+	var file *os.File
+	if *out != "" {
+		file, err = os.Create(*out)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", *out, err)
+		}
+		w = file
+	}
+
+	if err := export.Write(w, f, res); err != nil {
+		log.Fatalf("Failed to export results: %v", err)
+	}
+
+	if file != nil {
+		if err := file.Close(); err != nil {
+			log.Fatalf("Failed to write %s: %v", *out, err)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package fetch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DEFAULT_TIMEOUT = 60 * time.Second
+
+var ErrOffline = errors.New("not cached and offline mode is enabled")
+
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("fetching %s: %s", e.URL, e.Status)
+}
+
+func IsNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+type cacheMeta struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Fetched      time.Time `json:"fetched"`
+	// MaxAge is the max-age of the response's Cache-Control in seconds
+	MaxAge int `json:"max_age,omitempty"`
+}
+
+// fresh reports whether the cached response may be served without revalidating it.
+func (m *cacheMeta) fresh(ttl time.Duration) bool {
+	age := time.Since(m.Fetched)
+
+	return age < ttl || age < time.Duration(m.MaxAge)*time.Second
+}
+
+// Fetcher performs GET requests through an on-disk cache. Cached responses are served without a request
+// while they are fresh (Cache-Control max-age or TTL since they were fetched), then revalidated with
+// If-None-Match / If-Modified-Since and served as is when the network or the server is unavailable.
+// In offline mode only the cache is used.
+type Fetcher struct {
+	CacheDir string
+	Offline  bool
+	TTL      time.Duration
+	Client   *http.Client
+}
+
+func New(cacheDir string, offline bool, ttl time.Duration) *Fetcher {
+	return &Fetcher{
+		CacheDir: cacheDir,
+		Offline:  offline,
+		TTL:      ttl,
+		Client:   &http.Client{Timeout: DEFAULT_TIMEOUT},
+	}
+}
+
+var Default = New("", false, 0)
+
+func SetDefault(f *Fetcher) {
+	Default = f
+}
+
+func Get(url string) ([]byte, error) {
+	return Default.Get(url)
+}
+
+func (f *Fetcher) Get(url string) ([]byte, error) {
+	return f.get(url, false)
+}
+
+// GetPinned fetches a URL whose content never changes (e.g. a file at a commit), it is never revalidated once cached.
+func (f *Fetcher) GetPinned(url string) ([]byte, error) {
+	return f.get(url, true)
+}
+
+func (f *Fetcher) get(url string, pinned bool) ([]byte, error) {
+	body, meta := f.readCache(url)
+
+	if f.Offline {
+		if body == nil {
+			return nil, fmt.Errorf("fetching %s: %w", url, ErrOffline)
+		}
+		return body, nil
+	}
+
+	if body != nil && (pinned || meta != nil && meta.fresh(f.TTL)) {
+		return body, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil && meta != nil {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+
+	res, err := f.Client.Do(req)
+	if err != nil {
+		if body != nil {
+			log.Printf("Failed to fetch %s, serving cached copy: %v", url, err)
+			return body, nil
+		}
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified && body != nil {
+		if meta != nil {
+			meta.Fetched = time.Now()
+			meta.MaxAge = maxAge(res.Header.Get("Cache-Control"))
+			f.writeMeta(url, meta)
+		}
+		return body, nil
+	}
+
+	if res.StatusCode >= http.StatusInternalServerError && body != nil {
+		log.Printf("Failed to fetch %s, serving cached copy: %s", url, res.Status)
+		return body, nil
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, &StatusError{URL: url, StatusCode: res.StatusCode, Status: res.Status}
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	f.writeCache(url, data, &cacheMeta{
+		URL:          url,
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+		Fetched:      time.Now(),
+		MaxAge:       maxAge(res.Header.Get("Cache-Control")),
+	})
+
+	return data, nil
+}
+
+// maxAge returns the max-age of a Cache-Control header, 0 if the response has to be revalidated.
+func maxAge(cacheControl string) int {
+	age := 0
+
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+
+		switch strings.ToLower(name) {
+		case "no-cache", "no-store":
+			return 0
+		case "max-age":
+			if n, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && n > 0 {
+				age = n
+			}
+		}
+	}
+
+	return age
+}
+
+func (f *Fetcher) cachePath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(sum[:])
+
+	return filepath.Join(f.CacheDir, key[:2], key)
+}
+
+func (f *Fetcher) readCache(url string) ([]byte, *cacheMeta) {
+	if f.CacheDir == "" {
+		return nil, nil
+	}
+
+	p := f.cachePath(url)
+
+	body, err := os.ReadFile(p + ".body")
+	if err != nil {
+		return nil, nil
+	}
+
+	var meta cacheMeta
+	if contents, err := os.ReadFile(p + ".meta"); err == nil {
+		if err := json.Unmarshal(contents, &meta); err != nil || meta.URL != url {
+			return body, nil
+		}
+	}
+
+	return body, &meta
+}
+
+func (f *Fetcher) writeCache(url string, body []byte, meta *cacheMeta) {
+	if f.CacheDir == "" {
+		return
+	}
+
+	p := f.cachePath(url)
+
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		log.Printf("Failed to create cache directory: %v", err)
+		return
+	}
+
+	if err := writeAtomic(p+".body", body); err != nil {
+		log.Printf("Failed to cache %s: %v", url, err)
+		return
+	}
+
+	f.writeMeta(url, meta)
+}
+
+func (f *Fetcher) writeMeta(url string, meta *cacheMeta) {
+	m, err := json.Marshal(meta)
+	if err != nil {
+		return
+	}
+
+	if err := writeAtomic(f.cachePath(url)+".meta", m); err != nil {
+		log.Printf("Failed to cache %s: %v", url, err)
+	}
+}
+
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,57 @@
+package fetch
+
+import "net/url"
+
+const GITHUB_RAW_URL = "https://raw.githubusercontent.com"
+
+// Source is where a provider fetches its files from: BaseURL/Repo/Ref/<path>.
+// Repo and Ref may be empty for sources that aren't a repository (e.g. a rendered spec).
+type Source struct {
+	BaseURL string `toml:"base_url"`
+	Repo    string `toml:"repo"`
+	// Ref is a branch, tag or commit.
+	Ref string `toml:"ref"`
+}
+
+func (s Source) URL(elem ...string) (string, error) {
+	if s.Repo == "" && s.Ref == "" && len(elem) == 0 {
+		// keep BaseURL as is, JoinPath would drop a trailing slash
+		return s.BaseURL, nil
+	}
+
+	return url.JoinPath(s.BaseURL, append([]string{s.Repo, s.Ref}, elem...)...)
+}
+
+// Pinned reports whether Ref is a full commit hash, whose files never change.
+func (s Source) Pinned() bool {
+	return IsCommit(s.Ref)
+}
+
+// IsCommit reports whether ref is a full commit hash.
+func IsCommit(ref string) bool {
+	if len(ref) != 40 {
+		return false
+	}
+
+	for _, c := range ref {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Get fetches a file of the source with the default Fetcher, files of a pinned commit aren't revalidated.
+func (s Source) Get(elem ...string) ([]byte, error) {
+	u, err := s.URL(elem...)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Pinned() {
+		return Default.GetPinned(u)
+	}
+
+	return Get(u)
+}
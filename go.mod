@@ -4,11 +4,9 @@ go 1.24.6
 
 require (
 	github.com/BurntSushi/toml v1.5.0
+	github.com/google/jsonschema-go v0.2.1-0.20250825175020-748c325cec76
 	github.com/modelcontextprotocol/go-sdk v0.3.1
 	golang.org/x/net v0.43.0
 )
 
-require (
-	github.com/google/jsonschema-go v0.2.1-0.20250825175020-748c325cec76 // indirect
-	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
-)
+require github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
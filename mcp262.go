@@ -1,61 +1,231 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/Sharktheone/mcp262/fetch"
 	"github.com/Sharktheone/mcp262/runner"
 
 	"github.com/Sharktheone/mcp262/provider"
 	"github.com/Sharktheone/mcp262/provider/github"
+	"github.com/Sharktheone/mcp262/provider/live"
+	"github.com/Sharktheone/mcp262/provider/local"
+	"github.com/Sharktheone/mcp262/provider/workspace"
 	"github.com/Sharktheone/mcp262/provider/yavashark"
 	"github.com/Sharktheone/mcp262/tools"
+	"github.com/modelcontextprotocol/go-sdk/auth"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func main() {
 	config := runner.LoadConfig()
 
-	p, err := yavashark.NewYavasharkTestProvider()
+	var cacheTTL time.Duration
+	if config.CacheTTL != "" {
+		ttl, err := time.ParseDuration(config.CacheTTL)
+		if err != nil {
+			log.Fatalf("Invalid cache TTL %q: %v", config.CacheTTL, err)
+		}
+		cacheTTL = ttl
+	}
+
+	fetch.SetDefault(fetch.New(config.CacheDir, config.Offline, cacheTTL))
+
+	p, err := newTestProvider(config)
+	if err != nil {
+		log.Fatalf("Failed to create test provider: %v", err)
+		return
+	}
+
+	r, err := runner.NewFromConfig(config)
 	if err != nil {
-		log.Fatalf("Failed to create YavasharkTestProvider: %v", err)
+		log.Fatalf("Failed to create runner: %v", err)
 		return
 	}
 
+	if config.Live {
+		lp, err := live.NewLiveTestProvider(p)
+		if err != nil {
+			log.Fatalf("Failed to create LiveTestProvider: %v", err)
+			return
+		}
+
+		r.AddResultListener(lp.AddResults)
+		p = lp
+	}
+
+	if config.RefreshInterval != "" {
+		interval, err := time.ParseDuration(config.RefreshInterval)
+		if err != nil {
+			log.Fatalf("Invalid refresh interval %q: %v", config.RefreshInterval, err)
+			return
+		}
+
+		rp, ok := p.(provider.RefreshableProvider)
+		if !ok {
+			log.Fatalf("Test provider %s does not support refreshing", config.Provider)
+			return
+		}
+
+		go refreshPeriodically(rp, interval)
+	}
+
+	if tp, ok := p.(provider.TreeProvider); ok {
+		tp.Tree().AddListener(tools.NotifyStatusChanges)
+	}
+
 	provider.SetProvider(p)
-	provider.SetCodeProvider(github.NewGithubTest262CodeProvider())
-	provider.SetSpecProvider(github.NewGithubSpecProvider())
-	provider.SetRunner(runner.New(config.TestRootDir, config.RepoPath, config.Workers))
+	test262 := config.Test262Source()
+	if test262.Pinned() {
+		log.Printf("Fetching test262 code at %s", test262.Ref)
+	}
 
-	url := "0.0.0.0:8080"
+	provider.SetCodeProvider(workspace.NewWorkspaces(github.NewGithubTest262CodeProvider(test262)))
+	provider.SetSpecProvider(github.NewGithubSpecProvider(config.Sources.Spec, config.Sources.IntlSpec))
+	provider.SetRunner(r)
+
+	// without an engine checkout (e.g. only browsing CI results) the engine source tools report the missing provider
+	if src, err := local.NewLocalEngineSourceProvider(config.RepoPath); err != nil {
+		log.Printf("Engine source tools disabled, failed to create LocalEngineSourceProvider: %v", err)
+	} else {
+		provider.SetEngineSourceProvider(src)
+	}
 
-	server := mcp.NewServer(&mcp.Implementation{Name: "mcp262", Version: "v1.0.0", Title: "mcp262"}, nil)
+	if err := serve(config); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
+
+func newServer(policies ...tools.Policy) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "mcp262", Version: "v1.0.0", Title: "mcp262"}, &mcp.ServerOptions{
+		SubscribeHandler:   tools.Subscribe,
+		UnsubscribeHandler: tools.Unsubscribe,
+		CompletionHandler:  tools.Complete,
+		InitializedHandler: tools.TrackSession,
+	})
 
 	tools.AddTools(server)
 	tools.AddCodeTools(server)
 	tools.AddSpecTools(server)
 	tools.AddRunnerTools(server)
+	tools.AddEngineTools(server)
+	tools.AddWorkspaceTools(server)
+	tools.AddResources(server)
+	tools.AddPrompts(server)
 
-	handler := mcp.NewStreamableHTTPHandler(func(req *http.Request) *mcp.Server {
-		return server
-	}, nil)
+	server.AddReceivingMiddleware(tools.PolicyMiddleware(policies...))
 
-	//handler := mcp.NewSSEHandler(func(req *http.Request) *mcp.Server {
-	//	return server
-	//})
+	return server
+}
 
-	handlerWithLogging := loggingHandler(handler)
+func toPolicy(config runner.PolicyConfig) tools.Policy {
+	return tools.Policy{
+		ReadOnly: config.ReadOnly,
+		Allow:    config.AllowTools,
+		Deny:     config.DenyTools,
+	}
+}
 
-	log.Printf("MCP server listening on %s", url)
+func serve(config *runner.Config) error {
+	global := toPolicy(config.PolicyConfig)
 
-	if err := http.ListenAndServe(url, handlerWithLogging); err != nil {
-		log.Fatalf("Server failed: %v", err)
+	if config.Transport == runner.TRANSPORT_STDIO {
+		// stdout is the protocol stream, logs go to stderr
+		return newServer(global).Run(context.Background(), &mcp.StdioTransport{})
+	}
+
+	// every client gets its own server, so its sessions only see the tools it is allowed to use
+	servers := make(map[string]*mcp.Server, len(config.Auth.Clients)+1)
+	servers[""] = newServer(global)
+	servers[""].AddReceivingMiddleware(clientMiddleware(""))
+
+	for _, c := range config.Auth.Clients {
+		servers[c.Name] = newServer(global, toPolicy(c.PolicyConfig))
+		servers[c.Name].AddReceivingMiddleware(clientMiddleware(c.Name))
+	}
+
+	getServer := func(req *http.Request) *mcp.Server {
+		return servers[tokenClient(auth.TokenInfoFromContext(req.Context()))]
+	}
+
+	var handler http.Handler
+
+	switch config.Transport {
+	case runner.TRANSPORT_SSE:
+		handler = mcp.NewSSEHandler(getServer)
+	case runner.TRANSPORT_HTTP, "":
+		handler = mcp.NewStreamableHTTPHandler(getServer, nil)
+	default:
+		return fmt.Errorf("unknown transport: %s", config.Transport)
+	}
+
+	basePath := config.BasePath
+	if !strings.HasPrefix(basePath, "/") {
+		basePath = "/" + basePath
+	}
+
+	// not stripped, the SSE handler derives the message endpoint from the request path
+	mux := http.NewServeMux()
+	mux.Handle(basePath, handler)
+
+	tokens, err := loadTokens(&config.Auth)
+	if err != nil {
+		return fmt.Errorf("failed to load auth tokens: %w", err)
+	}
+
+	handler = mux
+	if len(tokens) > 0 {
+		handler = authHandler(tokens, handler)
+	} else {
+		log.Printf("No auth tokens configured, the MCP server accepts unauthenticated requests")
+	}
+
+	if (config.TLSCert == "") != (config.TLSKey == "") {
+		return errors.New("tls_cert and tls_key must be set together")
+	}
+
+	log.Printf("MCP server (%s) listening on %s%s", config.Transport, config.Listen, basePath)
+
+	if config.TLSCert != "" {
+		return http.ListenAndServeTLS(config.Listen, config.TLSCert, config.TLSKey, loggingHandler(handler))
 	}
 
-	//if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
-	//	log.Fatal(err)
-	//}
+	return http.ListenAndServe(config.Listen, loggingHandler(handler))
+}
+
+func newTestProvider(config *runner.Config) (provider.TestProvider, error) {
+	switch config.Provider {
+	case runner.PROVIDER_YAVASHARK, "":
+		return yavashark.NewYavasharkTestProvider(config.Sources.Results)
+	case runner.PROVIDER_YAVASHARK_DIR:
+		return yavashark.NewYavasharkDirTestProvider(config.DataDir)
+	case runner.PROVIDER_IMPORT:
+		return local.NewImportedTestProvider(config.Import.Format, config.Import.Path, config.Import.Engine)
+	default:
+		return nil, fmt.Errorf("unknown provider: %s", config.Provider)
+	}
+}
+
+func refreshPeriodically(rp provider.RefreshableProvider, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		res, err := rp.Refresh()
+		if err != nil {
+			log.Printf("Failed to refresh results: %v", err)
+			continue
+		}
+
+		log.Printf("Refreshed results: %d tests, %d added, %d removed, %d kept local, %d local replaced by CI, transitions: %v",
+			res.Total, res.Added, res.Removed, res.KeptLocal, res.DroppedLocal, res.Transitions)
+	}
 }
 
 type responseWriter struct {
@@ -68,6 +238,13 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Flush passes flushes through, SSE events are only sent once flushed.
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func loggingHandler(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -0,0 +1,167 @@
+package patch
+
+import (
+	"fmt"
+	"strings"
+)
+
+const CONTEXT = 3
+
+// MAX_LCS bounds the table of the line matching, larger changes are diffed as a single replacement.
+const MAX_LCS = 4_000_000
+
+type op struct {
+	kind byte
+	text string
+}
+
+// Diff returns the unified diff from old to new, empty if they have the same lines.
+func Diff(oldName string, newName string, old string, new string) string {
+	ops := diffLines(splitLines(old), splitLines(new))
+
+	var b strings.Builder
+
+	oldLine, newLine := 0, 0
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// extend the hunk while the next change is close enough for the contexts to touch
+		start := max(i-CONTEXT, 0)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*CONTEXT {
+				break
+			}
+		}
+		end = min(end+CONTEXT, len(ops))
+
+		oldStart, newStart := oldLine-(i-start), newLine-(i-start)
+		oldCount, newCount := 0, 0
+		for _, o := range ops[start:end] {
+			if o.kind != '+' {
+				oldCount++
+			}
+			if o.kind != '-' {
+				newCount++
+			}
+		}
+
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+		}
+
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		for _, o := range ops[start:end] {
+			b.WriteByte(o.kind)
+			b.WriteString(o.text)
+			b.WriteByte('\n')
+		}
+
+		oldLine, newLine = oldStart+oldCount, newStart+newCount
+		i = end
+	}
+
+	return b.String()
+}
+
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+
+	if count == 1 {
+		return fmt.Sprint(start + 1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// diffLines matches the lines of a and b with a longest common subsequence after trimming the common prefix and suffix.
+func diffLines(a []string, b []string) []op {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for _, l := range a[:pre] {
+		ops = append(ops, op{' ', l})
+	}
+
+	ma, mb := a[pre:len(a)-suf], b[pre:len(b)-suf]
+
+	if len(ma)*len(mb) > MAX_LCS {
+		for _, l := range ma {
+			ops = append(ops, op{'-', l})
+		}
+		for _, l := range mb {
+			ops = append(ops, op{'+', l})
+		}
+	} else {
+		ops = append(ops, lcs(ma, mb)...)
+	}
+
+	for _, l := range a[len(a)-suf:] {
+		ops = append(ops, op{' ', l})
+	}
+
+	return ops
+}
+
+func lcs(a []string, b []string) []op {
+	n, m := len(a), len(b)
+
+	// l[i][j] is the length of the common subsequence of a[i:] and b[j:]
+	l := make([][]int32, n+1)
+	for i := range l {
+		l[i] = make([]int32, m+1)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				l[i][j] = l[i+1][j+1] + 1
+			} else {
+				l[i][j] = max(l[i+1][j], l[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]op, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case l[i+1][j] >= l[i][j+1]:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+
+	for ; i < n; i++ {
+		ops = append(ops, op{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+
+	return ops
+}
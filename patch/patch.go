@@ -0,0 +1,286 @@
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// Hunk is a hunk of a unified diff, Lines keep their ' ', '-' or '+' prefix.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []string
+}
+
+// Conflict is a hunk or replacement that doesn't apply to the current code. Nothing is applied if there is one.
+type Conflict struct {
+	Index    int    `json:"index"`
+	Line     int    `json:"line"`
+	Reason   string `json:"reason"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// Replacement replaces the lines Start to End (1-based, inclusive) with Text, End = Start-1 inserts before Start.
+// If Old is set the current lines have to match it.
+type Replacement struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Old   string `json:"old,omitempty"`
+	Text  string `json:"text"`
+}
+
+// Parse reads the hunks of a unified diff of a single file. Headers before the first hunk are ignored and
+// the line counts of hunk headers aren't enforced, as hand-written diffs often get them wrong.
+func Parse(diff string) ([]Hunk, error) {
+	lines := splitLines(strings.ReplaceAll(diff, "\r\n", "\n"))
+
+	var hunks []Hunk
+	var cur *Hunk
+
+	for i := 0; i < len(lines); i++ {
+		l := lines[i]
+
+		switch {
+		case strings.HasPrefix(l, "@@"):
+			m := hunkHeader.FindStringSubmatch(l)
+			if m == nil {
+				return nil, fmt.Errorf("line %d: invalid hunk header %q", i+1, l)
+			}
+
+			hunks = append(hunks, Hunk{
+				OldStart: atoi(m[1], 0),
+				OldLines: atoi(m[2], 1),
+				NewStart: atoi(m[3], 0),
+				NewLines: atoi(m[4], 1),
+			})
+			cur = &hunks[len(hunks)-1]
+
+		case strings.HasPrefix(l, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			if cur != nil {
+				return nil, fmt.Errorf("line %d: the diff changes more than one file", i+1)
+			}
+			i++
+
+		case cur == nil:
+			// diff --git, index, ... headers
+
+		case strings.HasPrefix(l, `\`):
+			// \ No newline at end of file
+
+		case l == "":
+			// editors and models tend to strip the space of empty context lines
+			cur.Lines = append(cur.Lines, " ")
+
+		case l[0] == ' ' || l[0] == '-' || l[0] == '+':
+			cur.Lines = append(cur.Lines, l)
+
+		default:
+			return nil, fmt.Errorf("line %d: unexpected line in hunk %q", i+1, l)
+		}
+	}
+
+	if len(hunks) == 0 {
+		return nil, errors.New("the diff has no hunks")
+	}
+
+	for i := range hunks {
+		h := &hunks[i]
+		for len(h.Lines) > 0 && h.Lines[len(h.Lines)-1] == " " {
+			h.Lines = h.Lines[:len(h.Lines)-1]
+		}
+	}
+
+	return hunks, nil
+}
+
+// Apply applies the hunks in order. A hunk is applied where its context matches nearest to the line given
+// in its header, after the previous hunk.
+func Apply(code string, hunks []Hunk) (string, []Conflict) {
+	lines := splitLines(code)
+
+	out := make([]string, 0, len(lines))
+	var conflicts []Conflict
+	prev := 0
+
+	for i, h := range hunks {
+		var old, repl []string
+		for _, l := range h.Lines {
+			switch l[0] {
+			case ' ':
+				old = append(old, l[1:])
+				repl = append(repl, l[1:])
+			case '-':
+				old = append(old, l[1:])
+			case '+':
+				repl = append(repl, l[1:])
+			}
+		}
+
+		hint := h.OldStart - 1
+		if len(old) == 0 {
+			// @@ -n,0 @@ inserts after line n
+			hint = h.OldStart
+		}
+
+		pos := find(lines, old, prev, hint)
+		if pos < 0 {
+			at := min(max(hint, prev), len(lines))
+			conflicts = append(conflicts, Conflict{
+				Index:    i + 1,
+				Line:     at + 1,
+				Reason:   "the lines removed or kept by the hunk were not found",
+				Expected: strings.Join(old, "\n"),
+				Actual:   strings.Join(lines[at:min(at+len(old), len(lines))], "\n"),
+			})
+			continue
+		}
+
+		out = append(out, lines[prev:pos]...)
+		out = append(out, repl...)
+		prev = pos + len(old)
+	}
+
+	if len(conflicts) > 0 {
+		return "", conflicts
+	}
+
+	out = append(out, lines[prev:]...)
+
+	return joinLines(out, code), nil
+}
+
+// find returns the position nearest to hint where lines continues with want, at or after from.
+// Lines are compared exactly first, then ignoring trailing whitespace.
+func find(lines []string, want []string, from int, hint int) int {
+	last := len(lines) - len(want)
+	if last < from {
+		return -1
+	}
+
+	hint = min(max(hint, from), last)
+
+	for _, eq := range []func(a, b string) bool{
+		func(a, b string) bool { return a == b },
+		func(a, b string) bool { return strings.TrimRight(a, " \t") == strings.TrimRight(b, " \t") },
+	} {
+		for d := 0; hint-d >= from || hint+d <= last; d++ {
+			if p := hint - d; p >= from && matches(lines[p:], want, eq) {
+				return p
+			}
+
+			if p := hint + d; d > 0 && p <= last && matches(lines[p:], want, eq) {
+				return p
+			}
+		}
+	}
+
+	return -1
+}
+
+func matches(lines []string, want []string, eq func(a, b string) bool) bool {
+	for i, w := range want {
+		if !eq(lines[i], w) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Replace applies line-range replacements, all ranges refer to the lines of code before any replacement.
+func Replace(code string, reps []Replacement) (string, []Conflict) {
+	lines := splitLines(code)
+
+	order := make([]int, len(reps))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return reps[order[i]].Start < reps[order[j]].Start
+	})
+
+	var conflicts []Conflict
+	out := make([]string, 0, len(lines))
+	prev := 0
+
+	for _, i := range order {
+		r := reps[i]
+
+		conflict := Conflict{Index: i + 1, Line: r.Start}
+
+		switch {
+		case r.Start < 1 || r.End < r.Start-1 || r.End > len(lines):
+			conflict.Reason = fmt.Sprintf("invalid range %d-%d, the code has %d lines", r.Start, r.End, len(lines))
+		case r.Start-1 < prev:
+			conflict.Reason = "the range overlaps another replacement"
+		case r.Old != "" && strings.TrimSuffix(r.Old, "\n") != strings.Join(lines[r.Start-1:r.End], "\n"):
+			conflict.Reason = "the lines don't match old"
+			conflict.Expected = strings.TrimSuffix(r.Old, "\n")
+			conflict.Actual = strings.Join(lines[r.Start-1:r.End], "\n")
+			prev = r.End
+		default:
+			out = append(out, lines[prev:r.Start-1]...)
+			out = append(out, splitLines(r.Text)...)
+			prev = r.End
+			continue
+		}
+
+		conflicts = append(conflicts, conflict)
+	}
+
+	if len(conflicts) > 0 {
+		sort.Slice(conflicts, func(i, j int) bool {
+			return conflicts[i].Index < conflicts[j].Index
+		})
+		return "", conflicts
+	}
+
+	out = append(out, lines[prev:]...)
+
+	return joinLines(out, code), nil
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// joinLines keeps the final newline of the original code, new files get one.
+func joinLines(lines []string, orig string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+
+	s := strings.Join(lines, "\n")
+	if orig == "" || strings.HasSuffix(orig, "\n") {
+		s += "\n"
+	}
+
+	return s
+}
+
+func atoi(s string, def int) int {
+	if s == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+
+	return n
+}
@@ -0,0 +1,279 @@
+package patch
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+const code = "a\nb\nc\nd\ne\nf\ng\nh\n"
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		diff      string
+		want      string
+		conflicts int
+	}{
+		{
+			name: "change",
+			code: code,
+			diff: "--- a/x.js\n+++ b/x.js\n@@ -2,3 +2,3 @@\n b\n-c\n+C\n d\n",
+			want: "a\nb\nC\nd\ne\nf\ng\nh\n",
+		},
+		{
+			name: "insertion",
+			code: code,
+			diff: "@@ -3,0 +4,2 @@\n+x\n+y\n",
+			want: "a\nb\nc\nx\ny\nd\ne\nf\ng\nh\n",
+		},
+		{
+			name: "deletion",
+			code: code,
+			diff: "@@ -1,3 +1,1 @@\n-a\n-b\n c\n",
+			want: "c\nd\ne\nf\ng\nh\n",
+		},
+		{
+			name: "two hunks",
+			code: code,
+			diff: "@@ -1,2 +1,2 @@\n-a\n+A\n b\n@@ -7,2 +7,2 @@\n g\n-h\n+H\n",
+			want: "A\nb\nc\nd\ne\nf\ng\nH\n",
+		},
+		{
+			name: "wrong line numbers",
+			code: code,
+			diff: "@@ -1,2 +1,2 @@\n f\n-g\n+G\n",
+			want: "a\nb\nc\nd\ne\nf\nG\nh\n",
+		},
+		{
+			name: "stripped context space and trailing whitespace",
+			code: "a\n\nb  \nc\n",
+			diff: "@@ -1,4 +1,4 @@\n a\n\n-b\n+B\n c\n",
+			want: "a\n\nB\nc\n",
+		},
+		{
+			name: "no final newline",
+			code: "a\nb",
+			diff: "@@ -2 +2 @@\n-b\n+c\n\\ No newline at end of file\n",
+			want: "a\nc",
+		},
+		{
+			name:      "conflict",
+			code:      code,
+			diff:      "@@ -2,2 +2,2 @@\n b\n-x\n+y\n",
+			conflicts: 1,
+		},
+		{
+			name:      "hunks out of order",
+			code:      code,
+			diff:      "@@ -7 +7 @@\n-g\n+G\n@@ -1 +1 @@\n-a\n+A\n",
+			conflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hunks, err := Parse(tt.diff)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			got, conflicts := Apply(tt.code, hunks)
+			if len(conflicts) != tt.conflicts {
+				t.Fatalf("got %d conflicts %+v, want %d", len(conflicts), conflicts, tt.conflicts)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+	}{
+		{"no hunks", "--- a/x.js\n+++ b/x.js\n"},
+		{"invalid header", "@@ -a +b @@\n-a\n"},
+		{"unexpected line", "@@ -1 +1 @@\n-a\n*b\n"},
+		{"two files", "--- a/x.js\n+++ b/x.js\n@@ -1 +1 @@\n-a\n+b\n--- a/y.js\n+++ b/y.js\n@@ -1 +1 @@\n-a\n+b\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.diff); err == nil {
+				t.Errorf("Parse(%q) succeeded", tt.diff)
+			}
+		})
+	}
+}
+
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		name      string
+		reps      []Replacement
+		want      string
+		conflicts []string
+	}{
+		{
+			name: "replace",
+			reps: []Replacement{{Start: 2, End: 3, Text: "B\nC"}},
+			want: "a\nB\nC\nd\ne\nf\ng\nh\n",
+		},
+		{
+			name: "insert",
+			reps: []Replacement{{Start: 1, End: 0, Text: "x\n"}},
+			want: "x\na\nb\nc\nd\ne\nf\ng\nh\n",
+		},
+		{
+			name: "delete",
+			reps: []Replacement{{Start: 4, End: 8, Text: ""}},
+			want: "a\nb\nc\n",
+		},
+		{
+			name: "ranges refer to the original lines",
+			reps: []Replacement{{Start: 7, End: 7, Text: "G"}, {Start: 1, End: 1, Text: "A\nA2"}},
+			want: "A\nA2\nb\nc\nd\ne\nf\nG\nh\n",
+		},
+		{
+			name: "old matches",
+			reps: []Replacement{{Start: 2, End: 2, Old: "b\n", Text: "B"}},
+			want: "a\nB\nc\nd\ne\nf\ng\nh\n",
+		},
+		{
+			name:      "old doesn't match",
+			reps:      []Replacement{{Start: 2, End: 2, Old: "x", Text: "B"}},
+			conflicts: []string{"the lines don't match old"},
+		},
+		{
+			name:      "overlap",
+			reps:      []Replacement{{Start: 2, End: 4, Text: "x"}, {Start: 3, End: 3, Text: "y"}},
+			conflicts: []string{"the range overlaps another replacement"},
+		},
+		{
+			name:      "overlap after a mismatch",
+			reps:      []Replacement{{Start: 2, End: 4, Old: "x", Text: "x"}, {Start: 3, End: 3, Text: "y"}},
+			conflicts: []string{"the lines don't match old", "the range overlaps another replacement"},
+		},
+		{
+			name:      "out of range",
+			reps:      []Replacement{{Start: 8, End: 9, Text: "x"}},
+			conflicts: []string{"invalid range 8-9, the code has 8 lines"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Replace(code, tt.reps)
+			if len(conflicts) != len(tt.conflicts) {
+				t.Fatalf("got conflicts %+v, want %q", conflicts, tt.conflicts)
+			}
+			for i, c := range conflicts {
+				if c.Reason != tt.conflicts[i] {
+					t.Errorf("conflict %d: %q, want %q", i, c.Reason, tt.conflicts[i])
+				}
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			name: "same",
+			old:  code,
+			new:  code,
+			want: "",
+		},
+		{
+			name: "change",
+			old:  code,
+			new:  strings.Replace(code, "e\n", "E\n", 1),
+			want: "--- a\n+++ b\n@@ -2,7 +2,7 @@\n b\n c\n d\n-e\n+E\n f\n g\n h\n",
+		},
+		{
+			name: "new file",
+			old:  "",
+			new:  "a\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name: "deleted file",
+			old:  "a\nb\n",
+			new:  "",
+			want: "--- a\n+++ b\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff("a", "b", tt.old, tt.new); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRoundTrip checks that applying the diff of random edits gives back the edited code.
+func TestRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	words := []string{"a", "b", "c", "", "}", "foo();", "/re;}/g", "`${x}`"}
+
+	random := func(n int) []string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = words[rnd.Intn(len(words))]
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		old := random(rnd.Intn(30))
+
+		edited := make([]string, 0, len(old))
+		for _, l := range old {
+			switch rnd.Intn(6) {
+			case 0:
+			case 1:
+				edited = append(edited, random(1+rnd.Intn(3))...)
+			case 2:
+				edited = append(edited, l)
+				edited = append(edited, random(1)...)
+			default:
+				edited = append(edited, l)
+			}
+		}
+
+		a, b := joinLines(old, "\n"), joinLines(edited, "\n")
+
+		d := Diff("a", "b", a, b)
+		if d == "" {
+			if a != b {
+				t.Fatalf("empty diff for %q -> %q", a, b)
+			}
+			continue
+		}
+
+		hunks, err := Parse(d)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", d, err)
+		}
+
+		got, conflicts := Apply(a, hunks)
+		if len(conflicts) > 0 {
+			t.Fatalf("conflicts %+v applying %q to %q", conflicts, d, a)
+		}
+		if got != b {
+			t.Fatalf("applying %q to %q gives %q, want %q", d, a, got, b)
+		}
+	}
+}
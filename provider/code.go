@@ -19,3 +19,46 @@ var CodeProvider TestCodeProvider
 func SetCodeProvider(p TestCodeProvider) {
 	CodeProvider = p
 }
+
+// Overlay holds edited tests (relative to test/) and harness files (relative to harness/) by path.
+type Overlay struct {
+	Tests   map[string]string `json:"tests"`
+	Harness map[string]string `json:"harness"`
+}
+
+func (o Overlay) Empty() bool {
+	return len(o.Tests) == 0 && len(o.Harness) == 0
+}
+
+// EditWorkspace is a set of edits on top of the code of another TestCodeProvider.
+type EditWorkspace interface {
+	TestCodeProvider
+
+	Name() string
+	Overlay() Overlay
+	// Base is the provider the edits are made on top of, it has the original code.
+	Base() TestCodeProvider
+	// UpdateTestCode and UpdateHarnessCode atomically replace the code of a file with the result of update,
+	// which gets the current code. Nothing is changed if update returns an error.
+	UpdateTestCode(testPath string, update func(code string) (string, error)) error
+	UpdateHarnessCode(filePath string, update func(code string) (string, error)) error
+}
+
+type WorkspaceInfo struct {
+	Name          string `json:"name"`
+	Shared        bool   `json:"shared"`
+	Sessions      int    `json:"sessions"`
+	EditedTests   int    `json:"edited_tests"`
+	EditedHarness int    `json:"edited_harness"`
+}
+
+// WorkspaceProvider is implemented by code providers that scope edits to the MCP session.
+// Every session edits its own workspace unless it switched to a shared, named one.
+type WorkspaceProvider interface {
+	SessionWorkspace(session any) EditWorkspace
+	UseWorkspace(session any, name string) (EditWorkspace, error)
+	CloseSession(session any)
+
+	Workspaces() []WorkspaceInfo
+	DeleteWorkspace(name string) error
+}
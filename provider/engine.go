@@ -0,0 +1,33 @@
+package provider
+
+type EngineSourceProvider interface {
+	ReadFile(filePath string, startLine int, endLine int) (SourceSnippet, error)
+	ListDir(dir string) ([]SourceEntry, error)
+	Grep(pattern string, dir string, max int) ([]SourceMatch, error)
+}
+
+type SourceSnippet struct {
+	Path       string `json:"path"`
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+	TotalLines int    `json:"total_lines"`
+	Code       string `json:"code"`
+}
+
+type SourceEntry struct {
+	Path  string `json:"path"`
+	IsDir bool   `json:"is_dir"`
+	Size  int64  `json:"size,omitempty"`
+}
+
+type SourceMatch struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+var EngineSource EngineSourceProvider
+
+func SetEngineSourceProvider(p EngineSourceProvider) {
+	EngineSource = p
+}
@@ -2,27 +2,23 @@ package github
 
 import (
 	"errors"
-	"io"
-	"net/http"
-	"net/url"
+
+	"github.com/Sharktheone/mcp262/fetch"
 )
 
-const BaseUrl = "https://raw.githubusercontent.com/tc39/test262/refs/heads/main/"
+var DefaultTest262Source = fetch.Source{BaseURL: fetch.GITHUB_RAW_URL, Repo: "tc39/test262", Ref: "main"}
 
 var HarnessFiles = []string{
 	"assert.js",
 	"sta.js",
 }
 
-type GithubTest262CodeProvider struct{}
+type GithubTest262CodeProvider struct {
+	source fetch.Source
+}
 
 func (g GithubTest262CodeProvider) GetTestCode(testPath string) (string, error) {
-	codeUrl, err := url.JoinPath(BaseUrl, "test", testPath)
-	if err != nil {
-		return "", err
-	}
-
-	return fetchCodeFromUrl(codeUrl)
+	return g.fetchCode("test", testPath)
 }
 
 func (g GithubTest262CodeProvider) GetHarnessForTest(testPath string) (map[string]string, error) {
@@ -32,11 +28,7 @@ func (g GithubTest262CodeProvider) GetHarnessForTest(testPath string) (map[strin
 func (g GithubTest262CodeProvider) GetHarness() (map[string]string, error) {
 	harnessCode := make(map[string]string, 2)
 	for _, file := range HarnessFiles {
-		codeUrl, err := url.JoinPath(BaseUrl, "harness", file)
-		if err != nil {
-			return nil, err
-		}
-		code, err := fetchCodeFromUrl(codeUrl)
+		code, err := g.fetchCode("harness", file)
 		if err != nil {
 			return nil, err
 		}
@@ -51,12 +43,7 @@ func (g GithubTest262CodeProvider) GetHaressFiles() ([]string, error) {
 }
 
 func (g GithubTest262CodeProvider) GetHarnessCode(filePath string) (string, error) {
-	codeUrl, err := url.JoinPath(BaseUrl, "harness", filePath)
-	if err != nil {
-		return "", err
-	}
-
-	return fetchCodeFromUrl(codeUrl)
+	return g.fetchCode("harness", filePath)
 }
 
 func (g GithubTest262CodeProvider) GetHarnessFilesForTest(testPath string) ([]string, error) {
@@ -75,25 +62,15 @@ func (g GithubTest262CodeProvider) ResetEdits() error {
 	return nil
 }
 
-func NewGithubTest262CodeProvider() GithubTest262CodeProvider {
-	return GithubTest262CodeProvider{}
+func NewGithubTest262CodeProvider(source fetch.Source) GithubTest262CodeProvider {
+	return GithubTest262CodeProvider{source: source}
 }
 
-func fetchCodeFromUrl(codeUrl string) (string, error) {
-	res, err := http.Get(codeUrl)
-	if err != nil {
-		return "", err
-	}
-
-	defer res.Body.Close()
-
-	if res.StatusCode != 200 {
-		return "", err
-	}
-	bodyBytes, err := io.ReadAll(res.Body)
+func (g GithubTest262CodeProvider) fetchCode(elem ...string) (string, error) {
+	body, err := g.source.Get(elem...)
 	if err != nil {
 		return "", err
 	}
 
-	return string(bodyBytes), nil
+	return string(body), nil
 }
@@ -3,36 +3,42 @@ package github
 import (
 	"bytes"
 	"errors"
-	"net/http"
 	"strings"
 
+	"github.com/Sharktheone/mcp262/fetch"
 	"golang.org/x/net/html"
 )
 
-const SpecLocation = "https://raw.githubusercontent.com/tc39/ecma262/refs/heads/main/spec.html"
-const IntlSpecLocation = "https://tc39.es/ecma402/"
+const SPEC_FILE = "spec.html"
+
+var DefaultSpecSource = fetch.Source{BaseURL: fetch.GITHUB_RAW_URL, Repo: "tc39/ecma262", Ref: "main"}
+
+// DefaultIntlSpecSource is the rendered ECMA-402 spec, the repository only has the unbuilt sources.
+var DefaultIntlSpecSource = fetch.Source{BaseURL: "https://tc39.es/ecma402/"}
 
 type GithubSpecProvider struct {
 	Content  map[string]string
 	Sections []string
-}
 
-func NewGithubSpecProvider() *GithubSpecProvider {
-	return &GithubSpecProvider{}
+	spec     fetch.Source
+	intlSpec fetch.Source
 }
 
-func (g *GithubSpecProvider) initializeSpec(url string) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
+// NewGithubSpecProvider fetches SPEC_FILE from spec and the intl spec from the root of intlSpec.
+func NewGithubSpecProvider(spec fetch.Source, intlSpec fetch.Source) *GithubSpecProvider {
+	return &GithubSpecProvider{
+		spec:     spec,
+		intlSpec: intlSpec,
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != 200 {
-		return errors.New("failed to fetch spec")
+func (g *GithubSpecProvider) initializeSpec(source fetch.Source, elem ...string) error {
+	body, err := source.Get(elem...)
+	if err != nil {
+		return errors.Join(errors.New("failed to fetch spec"), err)
 	}
 
-	root, err := html.Parse(resp.Body)
+	root, err := html.Parse(bytes.NewReader(body))
 	if err != nil {
 		return err
 	}
@@ -69,11 +75,11 @@ func (g *GithubSpecProvider) Initialize() error {
 	}
 	g.Sections = g.Sections[:0]
 
-	if err := g.initializeSpec(IntlSpecLocation); err != nil {
+	if err := g.initializeSpec(g.intlSpec); err != nil {
 		return err
 	}
 
-	if err := g.initializeSpec(SpecLocation); err != nil {
+	if err := g.initializeSpec(g.spec, SPEC_FILE); err != nil {
 		return err
 	}
 
@@ -116,6 +122,16 @@ func (g *GithubSpecProvider) SpecForIntrinsic(intrinsic string) (string, error)
 	return "", errors.New("spec section not found")
 }
 
+func (g *GithubSpecProvider) GetSections() ([]string, error) {
+	if g.Content == nil {
+		if err := g.Initialize(); err != nil {
+			return nil, errors.Join(errors.New("spec provider not initialized"), err)
+		}
+	}
+
+	return g.Sections, nil
+}
+
 func (g *GithubSpecProvider) SearchSpec(query string) ([]string, error) {
 	if g.Content == nil {
 		if err := g.Initialize(); err != nil {
@@ -0,0 +1,87 @@
+package live
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Sharktheone/mcp262/provider"
+	"github.com/Sharktheone/mcp262/testtree"
+)
+
+// LiveTestProvider layers the results of local runs over the CI results of another provider.
+// Statuses of tests that were run locally are replaced in the underlying testtree.TestTree,
+// so every query reflects the latest local run.
+type LiveTestProvider struct {
+	provider.TestProvider
+
+	tree *testtree.TestTree
+
+	mu      sync.RWMutex
+	outputs map[string]provider.TestResult
+}
+
+func NewLiveTestProvider(base provider.TestProvider) (*LiveTestProvider, error) {
+	tp, ok := base.(provider.TreeProvider)
+	if !ok {
+		return nil, errors.New("live mode requires a test provider backed by a test tree")
+	}
+
+	return &LiveTestProvider{
+		TestProvider: base,
+		tree:         tp.Tree(),
+		outputs:      make(map[string]provider.TestResult),
+	}, nil
+}
+
+// AddResults overlays results of a local run. It can be registered as a provider.ResultListener.
+func (lp *LiveTestProvider) AddResults(results []provider.TestResult) {
+	now := time.Now()
+
+	files := make([]testtree.TestTreeFile, 0, len(results))
+
+	lp.mu.Lock()
+	for _, res := range results {
+		// results of edited sources belong to the session that made the edits
+		if res.Modified {
+			continue
+		}
+
+		// tests missing from the CI results (e.g. added to test262 since) are added
+		files = append(files, testtree.TestTreeFile{Path: res.TestPath, Status: res.Status, Source: testtree.SOURCE_LOCAL, Produced: now})
+		lp.outputs[res.TestPath] = res
+	}
+	lp.mu.Unlock()
+
+	lp.tree.SetStatuses(files)
+}
+
+func (lp *LiveTestProvider) GetTestOutput(testPath string) (string, string, error) {
+	lp.mu.RLock()
+	res, ok := lp.outputs[testPath]
+	lp.mu.RUnlock()
+
+	if ok {
+		return res.Output, res.Status, nil
+	}
+
+	return lp.TestProvider.GetTestOutput(testPath)
+}
+
+// Refresh refreshes the underlying provider. Tests that were run locally keep their local status.
+func (lp *LiveTestProvider) Refresh() (provider.RefreshResult, error) {
+	rp, ok := lp.TestProvider.(provider.RefreshableProvider)
+	if !ok {
+		return provider.RefreshResult{}, errors.New("test provider does not support refreshing")
+	}
+
+	return rp.Refresh()
+}
+
+func (lp *LiveTestProvider) GetTestSource(testPath string) (string, time.Time, error) {
+	return lp.tree.GetTestSource(testPath)
+}
+
+func (lp *LiveTestProvider) Tree() *testtree.TestTree {
+	return lp.tree
+}
@@ -0,0 +1,127 @@
+package local
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Sharktheone/mcp262/provider"
+	"github.com/Sharktheone/mcp262/runner/importer"
+	"github.com/Sharktheone/mcp262/runner/results"
+	"github.com/Sharktheone/mcp262/testtree"
+)
+
+// ResultsTestProvider serves a TestProvider from already loaded results, e.g. results imported from another test262 runner.
+type ResultsTestProvider struct {
+	*testtree.TestTree
+
+	mu       sync.RWMutex
+	messages map[string]string
+
+	// load reloads the results on Refresh, nil if they can't be reloaded
+	load func() (*results.TestResults, error)
+}
+
+func NewResultsTestProvider(tr *results.TestResults) *ResultsTestProvider {
+	tree, messages := newResultsTree(tr)
+
+	return &ResultsTestProvider{
+		TestTree: tree,
+		messages: messages,
+	}
+}
+
+func newResultsTree(tr *results.TestResults) (*testtree.TestTree, map[string]string) {
+	tree := testtree.NewTestTreeSize(len(tr.TestResults), 0)
+	messages := make(map[string]string)
+	loaded := time.Now()
+
+	for _, res := range tr.TestResults {
+		tree.AddFileFrom(res.Path, res.Status.String(), testtree.SOURCE_CI, loaded)
+
+		if res.Msg != "" {
+			messages[res.Path] = res.Msg
+		}
+	}
+
+	return tree, messages
+}
+
+// NewImportedTestProvider imports results in one of the importer formats and serves them as a TestProvider.
+func NewImportedTestProvider(format string, path string, engine string) (*ResultsTestProvider, error) {
+	f, err := importer.ParseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	load := func() (*results.TestResults, error) {
+		return importer.Load(f, path, engine)
+	}
+
+	tr, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	rp := NewResultsTestProvider(tr)
+	rp.load = load
+
+	return rp, nil
+}
+
+// Refresh imports the results again, e.g. after the file was replaced by a newer run.
+func (rp *ResultsTestProvider) Refresh() (provider.RefreshResult, error) {
+	if rp.load == nil {
+		return provider.RefreshResult{}, errors.New("results were not loaded from a source that can be refreshed")
+	}
+
+	tr, err := rp.load()
+	if err != nil {
+		return provider.RefreshResult{}, err
+	}
+
+	tree, messages := newResultsTree(tr)
+
+	rp.mu.Lock()
+	rp.messages = messages
+	rp.mu.Unlock()
+
+	changes := rp.Replace(tree)
+
+	return provider.NewRefreshResult(rp.TestTree, changes), nil
+}
+
+func (rp *ResultsTestProvider) GetFailedTestsInDir(dir string) ([]string, error) {
+	statuses, err := rp.GetTestStatusesInDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return testtree.FailedTests(statuses), nil
+}
+
+func (rp *ResultsTestProvider) GetFailedTestsInDirRec(dir string) ([]string, error) {
+	statuses, err := rp.GetTestStatusesInDirRec(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return testtree.FailedTests(statuses), nil
+}
+
+func (rp *ResultsTestProvider) GetTestOutput(testPath string) (string, string, error) {
+	s, err := rp.GetTestStatus(testPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	rp.mu.RLock()
+	msg, ok := rp.messages[testPath]
+	rp.mu.RUnlock()
+
+	if !ok {
+		return "", s, errors.New("no output recorded for test")
+	}
+
+	return msg, s, nil
+}
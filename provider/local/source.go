@@ -0,0 +1,260 @@
+package local
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Sharktheone/mcp262/provider"
+)
+
+const MAX_FILE_SIZE = 2 * 1024 * 1024
+const DEFAULT_GREP_MAX = 200
+
+var ErrOutsideRepo = errors.New("path is outside of the engine repository")
+
+// SkipDirs are never descended into when grepping the engine repository.
+var SkipDirs = []string{
+	".git",
+	"target",
+	"node_modules",
+	"test262",
+}
+
+type LocalEngineSourceProvider struct {
+	root string
+}
+
+func NewLocalEngineSourceProvider(root string) (*LocalEngineSourceProvider, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	abs, err = filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalEngineSourceProvider{root: abs}, nil
+}
+
+// resolve maps a repository relative (or absolute, but inside the repository) path to a path on disk
+// and rejects anything that escapes the repository root, including through symlinks.
+func (l *LocalEngineSourceProvider) resolve(p string) (string, error) {
+	if filepath.IsAbs(p) {
+		rel, err := filepath.Rel(l.root, p)
+		if err != nil {
+			return "", ErrOutsideRepo
+		}
+		p = rel
+	}
+
+	p = filepath.Clean(p)
+	if p == ".." || strings.HasPrefix(p, ".."+string(filepath.Separator)) {
+		return "", ErrOutsideRepo
+	}
+
+	full := filepath.Join(l.root, p)
+
+	resolved, err := filepath.EvalSymlinks(full)
+	if err != nil {
+		return "", err
+	}
+
+	if resolved != l.root && !strings.HasPrefix(resolved, l.root+string(filepath.Separator)) {
+		return "", ErrOutsideRepo
+	}
+
+	return resolved, nil
+}
+
+func (l *LocalEngineSourceProvider) rel(full string) string {
+	rel, err := filepath.Rel(l.root, full)
+	if err != nil {
+		return full
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+func (l *LocalEngineSourceProvider) ReadFile(filePath string, startLine int, endLine int) (provider.SourceSnippet, error) {
+	full, err := l.resolve(filePath)
+	if err != nil {
+		return provider.SourceSnippet{}, err
+	}
+
+	info, err := os.Stat(full)
+	if err != nil {
+		return provider.SourceSnippet{}, err
+	}
+
+	if info.IsDir() {
+		return provider.SourceSnippet{}, errors.New("path is a directory")
+	}
+
+	if info.Size() > MAX_FILE_SIZE {
+		return provider.SourceSnippet{}, fmt.Errorf("file too large (%d bytes)", info.Size())
+	}
+
+	contents, err := os.ReadFile(full)
+	if err != nil {
+		return provider.SourceSnippet{}, err
+	}
+
+	lines := strings.Split(string(contents), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	total := len(lines)
+
+	if startLine <= 0 {
+		startLine = 1
+	}
+
+	if endLine <= 0 || endLine > total {
+		endLine = total
+	}
+
+	if startLine > total {
+		return provider.SourceSnippet{}, fmt.Errorf("start line %d is past the end of the file (%d lines)", startLine, total)
+	}
+
+	if startLine > endLine {
+		return provider.SourceSnippet{}, fmt.Errorf("start line %d is after end line %d", startLine, endLine)
+	}
+
+	return provider.SourceSnippet{
+		Path:       l.rel(full),
+		StartLine:  startLine,
+		EndLine:    endLine,
+		TotalLines: total,
+		Code:       strings.Join(lines[startLine-1:endLine], "\n"),
+	}, nil
+}
+
+func (l *LocalEngineSourceProvider) ListDir(dir string) ([]provider.SourceEntry, error) {
+	full, err := l.resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(full)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]provider.SourceEntry, 0, len(entries))
+	for _, e := range entries {
+		entry := provider.SourceEntry{
+			Path:  l.rel(filepath.Join(full, e.Name())),
+			IsDir: e.IsDir(),
+		}
+
+		if !e.IsDir() {
+			if info, err := e.Info(); err == nil {
+				entry.Size = info.Size()
+			}
+		}
+
+		out = append(out, entry)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Path < out[j].Path
+	})
+
+	return out, nil
+}
+
+func (l *LocalEngineSourceProvider) Grep(pattern string, dir string, max int) ([]provider.SourceMatch, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	full, err := l.resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if max <= 0 {
+		max = DEFAULT_GREP_MAX
+	}
+
+	out := make([]provider.SourceMatch, 0)
+	errLimit := errors.New("limit reached")
+
+	err = filepath.WalkDir(full, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			if p != full && isSkipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil || info.Size() > MAX_FILE_SIZE {
+			return nil
+		}
+
+		contents, err := os.ReadFile(p)
+		if err != nil || bytes.IndexByte(contents, 0) != -1 {
+			return nil
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(contents))
+		scanner.Buffer(make([]byte, 0, 64*1024), MAX_FILE_SIZE)
+
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := scanner.Text()
+			if re.MatchString(text) {
+				out = append(out, provider.SourceMatch{
+					Path: l.rel(p),
+					Line: line,
+					Text: text,
+				})
+
+				if len(out) >= max {
+					return errLimit
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errLimit) {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func isSkipDir(name string) bool {
+	for _, s := range SkipDirs {
+		if name == s {
+			return true
+		}
+	}
+
+	return false
+}
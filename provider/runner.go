@@ -1,5 +1,7 @@
 package provider
 
+import "time"
+
 type TestRunner interface {
 	RerunTest(testPath string, rebuild bool) (TestResult, error)
 	RerunTestsInDir(dir string, rebuild bool) (map[string]TestResult, error)
@@ -7,13 +9,102 @@ type TestRunner interface {
 
 	RerunTestsInDirChanges(dir string, rebuild bool) ([]TestDiff, error)
 	RerunFailedTestsInDirChanges(dir string, rebuild bool) ([]TestDiff, error)
+	// DiffLastRun diffs the results of earlier reruns against the baseline.
+	DiffLastRun(dir string) ([]TestDiff, error)
+
+	// RankPanicLocations also returns the number of matching results without a panic location.
+	RankPanicLocations(dir string, status string) ([]RankedPanicLocation, int, error)
+
+	LastBuild() (*BuildStatus, error)
+	Builds() ([]BuildStatus, error)
+
+	ExportResults(dir string, format string, write bool) (ExportedResults, error)
+
+	LoadBaseline(format string, path string, engine string) (int, error)
+}
+
+// OverlayRunner is implemented by runners that can run tests with edits that only exist in memory.
+type OverlayRunner interface {
+	WithOverlay(overlay Overlay) TestRunner
+}
+
+// Snippet is JavaScript source run like a test, with test262 includes and flags (module, strict, async).
+type Snippet struct {
+	Source   string
+	Includes []string
+	Flags    []string
+	Timeout  time.Duration
+	Rebuild  bool
+}
+
+type SnippetResult struct {
+	Status   string         `json:"status"`
+	Output   string         `json:"output"`
+	Duration string         `json:"duration"`
+	MemoryKB uint64         `json:"memory_kb"`
+	Engine   string         `json:"engine,omitempty"`
+	Panic    *PanicLocation `json:"panic,omitempty"`
+}
+
+// SnippetRunner is implemented by runners that can run source that isn't part of the test tree.
+type SnippetRunner interface {
+	RunSnippet(snippet Snippet) (SnippetResult, error)
+}
+
+// MinimizedTest is the smallest version of a failing test found that still fails the same way.
+type MinimizedTest struct {
+	TestPath      string `json:"test_path"`
+	Status        string `json:"status"`
+	Signature     string `json:"signature"`
+	Code          string `json:"code"`
+	OriginalLines int    `json:"original_lines"`
+	Lines         int    `json:"lines"`
+	Statements    int    `json:"statements"`
+	Kept          int    `json:"kept"`
+	Runs          int    `json:"runs"`
+	Complete      bool   `json:"complete"`
+}
+
+// TestMinimizer is implemented by runners that can reduce a failing test to a minimal reproduction.
+type TestMinimizer interface {
+	MinimizeTest(testPath string, rebuild bool, maxRuns int) (MinimizedTest, error)
 }
 
+// ResultListener is called with every batch of results produced by a TestRunner.
+type ResultListener func(results []TestResult)
+
 type TestResult struct {
-	TestPath string `json:"test_path"`
-	Status   string `json:"status"`
-	Output   string `json:"output"`
-	Duration string `json:"duration"`
+	TestPath string         `json:"test_path"`
+	Status   string         `json:"status"`
+	Output   string         `json:"output"`
+	Duration string         `json:"duration"`
+	Engine   string         `json:"engine,omitempty"`
+	Modified bool           `json:"modified,omitempty"`
+	Panic    *PanicLocation `json:"panic,omitempty"`
+}
+
+type ExportedResults struct {
+	Format  string `json:"format"`
+	Count   int    `json:"count"`
+	Path    string `json:"path,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+type PanicLocation struct {
+	Message string `json:"message"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+// RankedPanicLocation repeats the PanicLocation fields, embedded structs are not flattened in inferred output schemas.
+type RankedPanicLocation struct {
+	Message string   `json:"message"`
+	File    string   `json:"file"`
+	Line    int      `json:"line"`
+	Column  int      `json:"column"`
+	Count   int      `json:"count"`
+	Tests   []string `json:"tests"`
 }
 
 type TestDiff struct {
@@ -22,6 +113,42 @@ type TestDiff struct {
 	Items []string `json:"items"`
 }
 
+type BuildDiagnostic struct {
+	Level    string `json:"level"`
+	Code     string `json:"code,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+	Rendered string `json:"rendered,omitempty"`
+}
+
+type BuildStatus struct {
+	Profile  string            `json:"profile"`
+	State    string            `json:"state"`
+	Success  bool              `json:"success"`
+	Commit   string            `json:"commit"`
+	Started  string            `json:"started"`
+	Duration string            `json:"duration"`
+	Errors   []BuildDiagnostic `json:"errors"`
+	Warnings []BuildDiagnostic `json:"warnings"`
+	Stderr   string            `json:"stderr,omitempty"`
+}
+
+// BuildError is returned by a TestRunner when rebuilding the engine failed.
+type BuildError struct {
+	Build *BuildStatus
+	Err   error
+}
+
+func (e *BuildError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BuildError) Unwrap() error {
+	return e.Err
+}
+
 var Runner TestRunner
 
 func SetRunner(r TestRunner) {
@@ -7,6 +7,11 @@ type SpecProvider interface {
 	SearchSections(query string) ([]string, error)
 }
 
+// SectionLister is implemented by spec providers that can list all section ids.
+type SectionLister interface {
+	GetSections() ([]string, error)
+}
+
 var Spec SpecProvider
 
 func SetSpecProvider(s SpecProvider) {
@@ -1,5 +1,11 @@
 package provider
 
+import (
+	"time"
+
+	"github.com/Sharktheone/mcp262/testtree"
+)
+
 type TestProvider interface {
 	NumTests() int
 	NumTestsInDir(dir string) (int, error)
@@ -27,6 +33,48 @@ type TestProvider interface {
 	GetTestOutput(testPath string) (string, string, error)
 }
 
+// TestSourceProvider is implemented by providers that know where a status comes from (CI or a local run).
+type TestSourceProvider interface {
+	GetTestSource(testPath string) (string, time.Time, error)
+}
+
+// TreeProvider is implemented by providers backed by a testtree.TestTree.
+type TreeProvider interface {
+	Tree() *testtree.TestTree
+}
+
+// RefreshableProvider is implemented by providers that can reload their results without a restart.
+type RefreshableProvider interface {
+	Refresh() (RefreshResult, error)
+}
+
+type RefreshResult struct {
+	Total        int            `json:"total"`
+	Added        int            `json:"added"`
+	Removed      int            `json:"removed"`
+	KeptLocal    int            `json:"kept_local"`
+	DroppedLocal int            `json:"dropped_local"`
+	Transitions  map[string]int `json:"transitions"`
+	Refreshed    string         `json:"refreshed"`
+}
+
+func NewRefreshResult(tree *testtree.TestTree, changes testtree.Changes) RefreshResult {
+	transitions := make(map[string]int, len(changes.Transitions))
+	for t, n := range changes.Transitions {
+		transitions[t.From+" -> "+t.To] = n
+	}
+
+	return RefreshResult{
+		Total:        tree.NumTests(),
+		Added:        changes.Added,
+		Removed:      changes.Removed,
+		KeptLocal:    changes.KeptLocal,
+		DroppedLocal: changes.DroppedLocal,
+		Transitions:  transitions,
+		Refreshed:    time.Now().Format(time.RFC3339),
+	}
+}
+
 var Provider TestProvider
 
 func SetProvider(p TestProvider) {
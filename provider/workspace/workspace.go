@@ -0,0 +1,402 @@
+package workspace
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Sharktheone/mcp262/provider"
+)
+
+// PRIVATE_PREFIX starts the names of the private workspaces of sessions.
+const PRIVATE_PREFIX = "session-"
+
+// Workspace keeps edits to tests and harness files in memory, reads fall back to the base provider.
+type Workspace struct {
+	name string
+	base provider.TestCodeProvider
+
+	mu      sync.RWMutex
+	tests   map[string]string
+	harness map[string]string
+}
+
+func newWorkspace(name string, base provider.TestCodeProvider) *Workspace {
+	return &Workspace{
+		name:    name,
+		base:    base,
+		tests:   make(map[string]string),
+		harness: make(map[string]string),
+	}
+}
+
+func (w *Workspace) Name() string {
+	return w.name
+}
+
+func (w *Workspace) Base() provider.TestCodeProvider {
+	return w.base
+}
+
+func (w *Workspace) Overlay() provider.Overlay {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	o := provider.Overlay{
+		Tests:   make(map[string]string, len(w.tests)),
+		Harness: make(map[string]string, len(w.harness)),
+	}
+
+	for p, code := range w.tests {
+		o.Tests[p] = code
+	}
+
+	for p, code := range w.harness {
+		o.Harness[p] = code
+	}
+
+	return o
+}
+
+func (w *Workspace) GetTestCode(testPath string) (string, error) {
+	w.mu.RLock()
+	code, ok := w.tests[testPath]
+	w.mu.RUnlock()
+
+	if ok {
+		return code, nil
+	}
+
+	return w.base.GetTestCode(testPath)
+}
+
+func (w *Workspace) GetHarnessForTest(testPath string) (map[string]string, error) {
+	h, err := w.base.GetHarnessForTest(testPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.overlayHarness(h, false), nil
+}
+
+func (w *Workspace) GetHarness() (map[string]string, error) {
+	h, err := w.base.GetHarness()
+	if err != nil {
+		return nil, err
+	}
+
+	return w.overlayHarness(h, true), nil
+}
+
+// overlayHarness replaces the edited files in h, files only added in the workspace are included if all is set.
+func (w *Workspace) overlayHarness(h map[string]string, all bool) map[string]string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	out := make(map[string]string, len(h))
+	for p, code := range h {
+		out[p] = code
+	}
+
+	for p, code := range w.harness {
+		if _, ok := out[p]; ok || all {
+			out[p] = code
+		}
+	}
+
+	return out
+}
+
+func (w *Workspace) GetHarnessCode(filePath string) (string, error) {
+	w.mu.RLock()
+	code, ok := w.harness[filePath]
+	w.mu.RUnlock()
+
+	if ok {
+		return code, nil
+	}
+
+	return w.base.GetHarnessCode(filePath)
+}
+
+func (w *Workspace) GetHaressFiles() ([]string, error) {
+	files, err := w.base.GetHaressFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	seen := make(map[string]bool, len(files))
+	out := make([]string, 0, len(files)+len(w.harness))
+	for _, f := range files {
+		seen[f] = true
+		out = append(out, f)
+	}
+
+	for f := range w.harness {
+		if !seen[f] {
+			out = append(out, f)
+		}
+	}
+
+	sort.Strings(out)
+
+	return out, nil
+}
+
+func (w *Workspace) GetHarnessFilesForTest(testPath string) ([]string, error) {
+	return w.base.GetHarnessFilesForTest(testPath)
+}
+
+func (w *Workspace) SetTestCode(testPath string, code string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.tests[testPath] = code
+	return nil
+}
+
+func (w *Workspace) SetHarnessCode(filePath string, code string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.harness[filePath] = code
+	return nil
+}
+
+func (w *Workspace) UpdateTestCode(testPath string, update func(code string) (string, error)) error {
+	return w.updateFile(func() map[string]string { return w.tests }, testPath, w.base.GetTestCode, update)
+}
+
+func (w *Workspace) UpdateHarnessCode(filePath string, update func(code string) (string, error)) error {
+	return w.updateFile(func() map[string]string { return w.harness }, filePath, w.base.GetHarnessCode, update)
+}
+
+// updateFile replaces the edit of p with the result of update, the base code is used if p isn't edited yet.
+// The base code is fetched without holding the lock, an edit made in the meantime takes precedence over it.
+func (w *Workspace) updateFile(edits func() map[string]string, p string, base func(string) (string, error), update func(string) (string, error)) error {
+	for {
+		w.mu.RLock()
+		_, edited := edits()[p]
+		w.mu.RUnlock()
+
+		var code string
+		if !edited {
+			var err error
+			if code, err = base(p); err != nil {
+				return err
+			}
+		}
+
+		w.mu.Lock()
+
+		if c, ok := edits()[p]; ok {
+			code = c
+		} else if edited {
+			// the edit was reset in the meantime, start over from the base code
+			w.mu.Unlock()
+			continue
+		}
+
+		next, err := update(code)
+		if err == nil {
+			edits()[p] = next
+		}
+
+		w.mu.Unlock()
+		return err
+	}
+}
+
+// ResetEdits drops the edits of this workspace only.
+func (w *Workspace) ResetEdits() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.tests = make(map[string]string)
+	w.harness = make(map[string]string)
+	return nil
+}
+
+func (w *Workspace) info(shared bool, sessions int) provider.WorkspaceInfo {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return provider.WorkspaceInfo{
+		Name:          w.name,
+		Shared:        shared,
+		Sessions:      sessions,
+		EditedTests:   len(w.tests),
+		EditedHarness: len(w.harness),
+	}
+}
+
+// Workspaces scopes edits on top of a base code provider to MCP sessions. Each session gets a private workspace
+// on first use, or uses a named workspace shared with the other sessions using it.
+// Used as a plain provider.TestCodeProvider it edits the workspace of the nil session.
+type Workspaces struct {
+	base provider.TestCodeProvider
+
+	mu       sync.Mutex
+	named    map[string]*Workspace
+	private  map[any]*Workspace
+	sessions map[any]*Workspace
+	next     int
+}
+
+func NewWorkspaces(base provider.TestCodeProvider) *Workspaces {
+	return &Workspaces{
+		base:     base,
+		named:    make(map[string]*Workspace),
+		private:  make(map[any]*Workspace),
+		sessions: make(map[any]*Workspace),
+	}
+}
+
+func (ws *Workspaces) SessionWorkspace(session any) provider.EditWorkspace {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.sessionWorkspace(session)
+}
+
+func (ws *Workspaces) sessionWorkspace(session any) *Workspace {
+	if w, ok := ws.sessions[session]; ok {
+		return w
+	}
+
+	w, ok := ws.private[session]
+	if !ok {
+		ws.next++
+		w = newWorkspace(PRIVATE_PREFIX+strconv.Itoa(ws.next), ws.base)
+		ws.private[session] = w
+	}
+
+	ws.sessions[session] = w
+
+	return w
+}
+
+// UseWorkspace switches the session to the named workspace, creating it if needed.
+// An empty name switches back to the private workspace of the session.
+func (ws *Workspaces) UseWorkspace(session any, name string) (provider.EditWorkspace, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if name == "" {
+		delete(ws.sessions, session)
+		return ws.sessionWorkspace(session), nil
+	}
+
+	if strings.HasPrefix(name, PRIVATE_PREFIX) {
+		return nil, errors.New("workspace names starting with " + PRIVATE_PREFIX + " are reserved for private workspaces")
+	}
+
+	w, ok := ws.named[name]
+	if !ok {
+		w = newWorkspace(name, ws.base)
+		ws.named[name] = w
+	}
+
+	ws.sessions[session] = w
+
+	return w, nil
+}
+
+// CloseSession drops the private workspace of a session, named workspaces are kept.
+func (ws *Workspaces) CloseSession(session any) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	delete(ws.sessions, session)
+	delete(ws.private, session)
+}
+
+func (ws *Workspaces) Workspaces() []provider.WorkspaceInfo {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	users := make(map[*Workspace]int)
+	for _, w := range ws.sessions {
+		users[w]++
+	}
+
+	out := make([]provider.WorkspaceInfo, 0, len(ws.named)+len(ws.private))
+	for _, w := range ws.named {
+		out = append(out, w.info(true, users[w]))
+	}
+
+	for _, w := range ws.private {
+		out = append(out, w.info(false, users[w]))
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Shared != out[j].Shared {
+			return out[i].Shared
+		}
+		return out[i].Name < out[j].Name
+	})
+
+	return out
+}
+
+// DeleteWorkspace drops a named workspace, sessions using it switch back to their private workspace.
+func (ws *Workspaces) DeleteWorkspace(name string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	w, ok := ws.named[name]
+	if !ok {
+		return errors.New("no workspace named " + name)
+	}
+
+	delete(ws.named, name)
+
+	for s, sw := range ws.sessions {
+		if sw == w {
+			delete(ws.sessions, s)
+		}
+	}
+
+	return nil
+}
+
+func (ws *Workspaces) GetTestCode(testPath string) (string, error) {
+	return ws.SessionWorkspace(nil).GetTestCode(testPath)
+}
+
+func (ws *Workspaces) GetHarnessForTest(testPath string) (map[string]string, error) {
+	return ws.SessionWorkspace(nil).GetHarnessForTest(testPath)
+}
+
+func (ws *Workspaces) GetHarness() (map[string]string, error) {
+	return ws.SessionWorkspace(nil).GetHarness()
+}
+
+func (ws *Workspaces) GetHarnessCode(filePath string) (string, error) {
+	return ws.SessionWorkspace(nil).GetHarnessCode(filePath)
+}
+
+func (ws *Workspaces) GetHaressFiles() ([]string, error) {
+	return ws.SessionWorkspace(nil).GetHaressFiles()
+}
+
+func (ws *Workspaces) GetHarnessFilesForTest(testPath string) ([]string, error) {
+	return ws.SessionWorkspace(nil).GetHarnessFilesForTest(testPath)
+}
+
+func (ws *Workspaces) SetTestCode(testPath string, code string) error {
+	return ws.SessionWorkspace(nil).SetTestCode(testPath, code)
+}
+
+func (ws *Workspaces) SetHarnessCode(filePath string, code string) error {
+	return ws.SessionWorkspace(nil).SetHarnessCode(filePath, code)
+}
+
+func (ws *Workspaces) ResetEdits() error {
+	return ws.SessionWorkspace(nil).ResetEdits()
+}
@@ -0,0 +1,78 @@
+package yavashark
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/Sharktheone/mcp262/provider"
+)
+
+// YavasharkDirTestProvider serves the same data as YavasharkTestProvider from a local directory
+// with the yavashark-data layout (results.json and results/<path>.json).
+type YavasharkDirTestProvider struct {
+	*YavasharkTestProvider
+
+	dir string
+}
+
+func NewYavasharkDirTestProvider(dir string) (*YavasharkDirTestProvider, error) {
+	results, err := loadDirResults(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &YavasharkDirTestProvider{
+		YavasharkTestProvider: &YavasharkTestProvider{TestTree: newTestTree(results)},
+		dir:                   dir,
+	}, nil
+}
+
+// Refresh rereads results.json, e.g. after the data directory was pulled.
+func (yd *YavasharkDirTestProvider) Refresh() (provider.RefreshResult, error) {
+	results, err := loadDirResults(yd.dir)
+	if err != nil {
+		return provider.RefreshResult{}, err
+	}
+
+	changes := yd.Replace(newTestTree(results))
+
+	return provider.NewRefreshResult(yd.TestTree, changes), nil
+}
+
+func loadDirResults(dir string) ([]YavasharkTestResult, error) {
+	f, err := os.Open(filepath.Join(dir, "results.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var results []YavasharkTestResult
+	if err := json.NewDecoder(f).Decode(&results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (yd *YavasharkDirTestProvider) GetTestOutput(testPath string) (string, string, error) {
+	// cleaning the rooted path keeps it inside the data directory
+	p := filepath.Clean("/" + testPath)
+
+	contents, err := os.ReadFile(filepath.Join(yd.dir, "results", p+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", errors.New("test output not found")
+		}
+		return "", "", err
+	}
+
+	var result YavasharkResult
+	if err := json.Unmarshal(contents, &result); err != nil {
+		return "", "", err
+	}
+
+	return result.Message, result.Status, nil
+}
@@ -3,17 +3,15 @@ package yavashark
 import (
 	"encoding/json"
 	"errors"
-	"net/http"
-	"net/url"
-	"slices"
+	"time"
 
+	"github.com/Sharktheone/mcp262/fetch"
+	"github.com/Sharktheone/mcp262/provider"
 	"github.com/Sharktheone/mcp262/testtree"
 )
 
-const RESULTS_URL = "https://raw.githubusercontent.com/Sharktheone/yavashark-data/refs/heads/main/results.json"
-const BASE_RESULT_URL = "https://raw.githubusercontent.com/Sharktheone/yavashark-data/refs/heads/main/results"
-
-var FailedStatuses = []string{"FAIL", "TIMEOUT", "CRASH", "NOT_IMPLEMENTED", "RUNNER_ERROR", "ERROR"}
+// DefaultResultsSource is the yavashark-data repository CI results are pushed to.
+var DefaultResultsSource = fetch.Source{BaseURL: fetch.GITHUB_RAW_URL, Repo: "Sharktheone/yavashark-data", Ref: "main"}
 
 type YavasharkResult struct {
 	Status   string `json:"status"`
@@ -25,6 +23,8 @@ type YavasharkResult struct {
 
 type YavasharkTestProvider struct {
 	*testtree.TestTree
+
+	source fetch.Source
 }
 
 type YavasharkTestResult struct {
@@ -32,89 +32,86 @@ type YavasharkTestResult struct {
 	Status string `json:"s"`
 }
 
-func NewYavasharkTestProvider() (*YavasharkTestProvider, error) {
-	tree := testtree.NewTestTree()
+func NewYavasharkTestProvider(source fetch.Source) (*YavasharkTestProvider, error) {
+	results, err := fetchResults(source)
+	if err != nil {
+		return nil, err
+	}
 
-	// Load results from the RESULTS_URL
+	return &YavasharkTestProvider{
+		TestTree: newTestTree(results),
+		source:   source,
+	}, nil
+}
 
-	res, err := http.Get(RESULTS_URL)
+// Refresh fetches the latest CI results and swaps them in.
+func (yt *YavasharkTestProvider) Refresh() (provider.RefreshResult, error) {
+	results, err := fetchResults(yt.source)
 	if err != nil {
-		return nil, err
+		return provider.RefreshResult{}, err
 	}
 
-	defer res.Body.Close()
+	changes := yt.Replace(newTestTree(results))
+
+	return provider.NewRefreshResult(yt.TestTree, changes), nil
+}
 
-	if res.StatusCode != 200 {
-		return nil, errors.New("failed to fetch Yavashark results, status code: " + res.Status)
+func fetchResults(source fetch.Source) ([]YavasharkTestResult, error) {
+	body, err := source.Get("results.json")
+	if err != nil {
+		return nil, errors.Join(errors.New("failed to fetch Yavashark results"), err)
 	}
 
 	var results []YavasharkTestResult
-	err = json.NewDecoder(res.Body).Decode(&results)
+	err = json.Unmarshal(body, &results)
 
 	if err != nil {
 		return nil, err
 	}
 
+	return results, nil
+}
+
+func newTestTree(results []YavasharkTestResult) *testtree.TestTree {
+	tree := testtree.NewTestTreeSize(len(results), 0)
+	loaded := time.Now()
+
 	for _, result := range results {
-		tree.AddFile(result.Path, expandShortStatus(result.Status))
+		tree.AddFileFrom(result.Path, expandShortStatus(result.Status), testtree.SOURCE_CI, loaded)
 	}
 
-	return &YavasharkTestProvider{
-		tree,
-	}, nil
+	return tree
 }
 
 func (yt *YavasharkTestProvider) GetFailedTestsInDir(dir string) ([]string, error) {
-	if d, exists := yt.Directories[dir]; exists {
-		out := make([]string, 0)
-		for p, f := range d.Files {
-			if slices.Contains(FailedStatuses, f.Status) {
-				out = append(out, p)
-			}
-		}
-		return out, nil
+	statuses, err := yt.GetTestStatusesInDir(dir)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("directory not found")
+
+	return testtree.FailedTests(statuses), nil
 }
 
 func (yt *YavasharkTestProvider) GetFailedTestsInDirRec(dir string) ([]string, error) {
-	if d, exists := yt.Directories[dir]; exists {
-		out := make([]string, 0)
-		var walk func(*testtree.TestTreeDir)
-		walk = func(td *testtree.TestTreeDir) {
-			for p, f := range td.Files {
-				if slices.Contains(FailedStatuses, f.Status) {
-					out = append(out, p)
-				}
-			}
-			for _, sub := range td.Directories {
-				walk(sub)
-			}
-		}
-		walk(d)
-		return out, nil
+	statuses, err := yt.GetTestStatusesInDirRec(dir)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("directory not found")
+
+	return testtree.FailedTests(statuses), nil
 }
 
 func (yt *YavasharkTestProvider) GetTestOutput(testPath string) (string, string, error) {
-	urlString, err := url.JoinPath(BASE_RESULT_URL, testPath+".json")
-	if err != nil {
-		return "", "", err
-	}
-
-	res, err := http.Get(urlString)
+	body, err := yt.source.Get("results", testPath+".json")
 	if err != nil {
+		if fetch.IsNotFound(err) {
+			return "", "", errors.New("test output not found")
+		}
 		return "", "", err
 	}
 
-	defer res.Body.Close()
-	if res.StatusCode != 200 {
-		return "", "", errors.New("test output not found")
-	}
-
 	var result YavasharkResult
-	err = json.NewDecoder(res.Body).Decode(&result)
+	err = json.Unmarshal(body, &result)
 	if err != nil {
 		return "", "", err
 	}
@@ -2,14 +2,12 @@ package ci
 
 import (
 	"encoding/json"
-	"net/http"
 	"os"
 
+	"github.com/Sharktheone/mcp262/fetch"
 	"github.com/Sharktheone/mcp262/runner/results"
 )
 
-const CI_GITHUB_URL = "https://raw.githubusercontent.com/Sharktheone/yavashark-data/main/results.json"
-
 func LoadPrevCi(path string) (*results.TestResults, error) {
 	contents, err := os.ReadFile(path)
 	if err != nil {
@@ -32,20 +30,15 @@ func LoadPrevCi(path string) (*results.TestResults, error) {
 	return results.FromResults(res), nil
 }
 
-func LoadPrevCiGithub() (*results.TestResults, error) {
-	res, err := http.Get(CI_GITHUB_URL)
+// LoadPrevCiGithub loads the CI results (results.json) of a yavashark-data repository.
+func LoadPrevCiGithub(source fetch.Source) (*results.TestResults, error) {
+	body, err := source.Get("results.json")
 	if err != nil {
 		return nil, err
 	}
 
-	defer res.Body.Close()
-
-	if res.StatusCode != 200 {
-		return nil, err
-	}
-
 	var resultsCI []results.CIResult
-	err = json.NewDecoder(res.Body).Decode(&resultsCI)
+	err = json.Unmarshal(body, &resultsCI)
 
 	if err != nil {
 		return nil, err
@@ -6,29 +6,148 @@ import (
 	"github.com/BurntSushi/toml"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 const DEFAULT_WORKERS = 256
 const DEFAULT_TEST_ROOT = "./test262/test"
 const REPO_PATH = "./"
 
+const DEFAULT_LISTEN = "0.0.0.0:8080"
+const DEFAULT_BASE_PATH = "/"
+
+const (
+	TRANSPORT_STDIO = "stdio"
+	TRANSPORT_SSE   = "sse"
+	TRANSPORT_HTTP  = "http"
+)
+
+const (
+	PROVIDER_YAVASHARK     = "yavashark"
+	PROVIDER_YAVASHARK_DIR = "yavashark-dir"
+	PROVIDER_IMPORT        = "import"
+)
+
 type Config struct {
+	// Transport is the MCP transport: stdio, sse or http (streamable HTTP).
+	// Listen and BasePath are the address and path the sse and http transports are served on.
+	Transport string `toml:"transport"`
+	Listen    string `toml:"listen"`
+	BasePath  string `toml:"base_path"`
+	// Auth requires a bearer token for the sse and http transports, TLSCert and TLSKey serve them over HTTPS.
+	Auth    AuthConfig `toml:"auth"`
+	TLSCert string     `toml:"tls_cert"`
+	TLSKey  string     `toml:"tls_key"`
+	// PolicyConfig restricts the tools of every client.
+	PolicyConfig
+
 	RepoPath    string `toml:"repo_path"`
 	Workers     int    `toml:"workers"`
 	TestRootDir string `toml:"test_root_dir"`
+	OutputDir   string `toml:"output_dir"`
+
+	// Provider selects where test statuses come from: yavashark (CI results), yavashark-dir or import.
+	Provider string `toml:"provider"`
+	// DataDir is a local yavashark-data checkout used by the yavashark-dir provider.
+	DataDir string       `toml:"data_dir"`
+	Import  ImportConfig `toml:"import"`
+	// Live overlays the results of local runs over the provider's results.
+	Live bool `toml:"live"`
+	// RefreshInterval periodically refreshes the provider's results (Go duration, e.g. "15m"); empty disables it.
+	RefreshInterval string `toml:"refresh_interval"`
+
+	// CacheDir caches all HTTP fetches on disk, Offline serves them only from that cache.
+	CacheDir string `toml:"cache_dir"`
+	Offline  bool   `toml:"offline"`
+	// CacheTTL serves cached fetches without revalidating them for a while (Go duration, e.g. "10m"), on top of the max-age of the response.
+	CacheTTL string `toml:"cache_ttl"`
+	// Baseline replaces the yavashark CI results that reruns are diffed against.
+	Baseline ImportConfig `toml:"baseline"`
+	// BaselineDir is the directory LoadBaseline loads client-named results from; empty disables loading baselines from clients.
+	BaselineDir string `toml:"baseline_dir"`
+
+	Sources SourcesConfig `toml:"sources"`
+}
+
+// AuthConfig holds static bearer tokens, authentication is disabled if there are none.
+type AuthConfig struct {
+	Tokens []string `toml:"tokens"`
+	// TokenFile has one token per line.
+	TokenFile string `toml:"token_file"`
+	// Clients are tokens with their own tool policy, on top of the global one.
+	Clients []ClientConfig `toml:"clients"`
+}
+
+type ClientConfig struct {
+	Name  string `toml:"name"`
+	Token string `toml:"token"`
+	PolicyConfig
+}
+
+// PolicyConfig decides which tools are listed and callable. AllowTools and DenyTools hold
+// tool names or patterns like "Rerun*"; ReadOnly hides the tools that edit code or start runs.
+type PolicyConfig struct {
+	ReadOnly   bool     `toml:"read_only"`
+	AllowTools []string `toml:"allow_tools"`
+	DenyTools  []string `toml:"deny_tools"`
+}
+
+type ImportConfig struct {
+	Format string `toml:"format"`
+	Path   string `toml:"path"`
+	Engine string `toml:"engine"`
 }
 
 func NewConfig() *Config {
 	return &Config{
+		Transport:   TRANSPORT_HTTP,
+		Listen:      DEFAULT_LISTEN,
+		BasePath:    DEFAULT_BASE_PATH,
 		RepoPath:    REPO_PATH,
 		Workers:     DEFAULT_WORKERS,
 		TestRootDir: DEFAULT_TEST_ROOT,
+		Provider:    PROVIDER_YAVASHARK,
+		CacheDir:    defaultCacheDir(),
+		Sources:     NewSourcesConfig(),
 	}
 }
 
 func NewFromEnv() *Config {
 	config := NewConfig()
 
+	if transport, exists := os.LookupEnv("TRANSPORT"); exists {
+		config.Transport = transport
+	}
+
+	if listen, exists := os.LookupEnv("LISTEN"); exists {
+		config.Listen = listen
+	}
+
+	if basePath, exists := os.LookupEnv("BASE_PATH"); exists {
+		config.BasePath = basePath
+	}
+
+	if tokens, exists := os.LookupEnv("AUTH_TOKENS"); exists {
+		config.Auth.Tokens = strings.Split(tokens, ",")
+	}
+
+	if tokenFile, exists := os.LookupEnv("AUTH_TOKEN_FILE"); exists {
+		config.Auth.TokenFile = tokenFile
+	}
+
+	if readOnly, exists := os.LookupEnv("READ_ONLY"); exists {
+		config.ReadOnly = parseBool(readOnly)
+	}
+
+	if cert, exists := os.LookupEnv("TLS_CERT"); exists {
+		config.TLSCert = cert
+	}
+
+	if key, exists := os.LookupEnv("TLS_KEY"); exists {
+		config.TLSKey = key
+	}
+
 	if repoPath, exists := os.LookupEnv("REPO_PATH"); exists {
 		config.RepoPath = repoPath
 	}
@@ -45,6 +164,58 @@ func NewFromEnv() *Config {
 		config.TestRootDir = testRoot
 	}
 
+	if outputDir, exists := os.LookupEnv("OUTPUT_DIR"); exists {
+		config.OutputDir = outputDir
+	}
+
+	if provider, exists := os.LookupEnv("PROVIDER"); exists {
+		config.Provider = provider
+	}
+
+	if dataDir, exists := os.LookupEnv("DATA_DIR"); exists {
+		config.DataDir = dataDir
+	}
+
+	if baselineDir, exists := os.LookupEnv("BASELINE_DIR"); exists {
+		config.BaselineDir = baselineDir
+	}
+
+	if live, exists := os.LookupEnv("LIVE"); exists {
+		config.Live = parseBool(live)
+	}
+
+	if interval, exists := os.LookupEnv("REFRESH_INTERVAL"); exists {
+		config.RefreshInterval = interval
+	}
+
+	if cacheDir, exists := os.LookupEnv("CACHE_DIR"); exists {
+		config.CacheDir = cacheDir
+	}
+
+	if cacheTTL, exists := os.LookupEnv("CACHE_TTL"); exists {
+		config.CacheTTL = cacheTTL
+	}
+
+	if offline, exists := os.LookupEnv("OFFLINE"); exists {
+		config.Offline = parseBool(offline)
+	}
+
+	if ref, exists := os.LookupEnv("TEST262_REF"); exists {
+		config.Sources.Test262.Ref = ref
+	}
+
+	if ref, exists := os.LookupEnv("RESULTS_REF"); exists {
+		config.Sources.Results.Ref = ref
+	}
+
+	if pin, exists := os.LookupEnv("PIN_TEST262"); exists {
+		config.Sources.PinTest262 = parseBool(pin)
+	}
+
+	if commitPath, exists := os.LookupEnv("TEST262_COMMIT_PATH"); exists {
+		config.Sources.Test262CommitPath = commitPath
+	}
+
 	return config
 }
 
@@ -52,9 +223,34 @@ func LoadConfig() *Config {
 	config := NewFromEnv()
 
 	configFile := flag.String("config", "config.toml", "Path to TOML config file")
+	transport := flag.String("transport", config.Transport, "MCP transport: stdio, sse or http")
+	listen := flag.String("listen", config.Listen, "Address the sse and http transports listen on")
+	basePath := flag.String("base_path", config.BasePath, "Path the sse and http transports are served on")
+	tokenFile := flag.String("token_file", config.Auth.TokenFile, "File with bearer tokens accepted by the sse and http transports, one per line")
+	readOnly := flag.Bool("read_only", config.ReadOnly, "Hide the tools that edit code or start runs")
+	tlsCert := flag.String("tls_cert", config.TLSCert, "TLS certificate for the sse and http transports")
+	tlsKey := flag.String("tls_key", config.TLSKey, "TLS key for the sse and http transports")
 	repoPath := flag.String("repo", config.RepoPath, "Path to external repository for CI results")
 	workers := flag.Int("workers", config.Workers, "Number of workers")
 	testRootDir := flag.String("test_root", config.TestRootDir, "Path to test root directory")
+	outputDir := flag.String("output_dir", config.OutputDir, "Directory exported results are written to")
+	provider := flag.String("provider", config.Provider, "Test provider: yavashark, yavashark-dir or import")
+	dataDir := flag.String("data_dir", config.DataDir, "Local yavashark-data checkout for the yavashark-dir provider")
+	baselineDir := flag.String("baseline_dir", config.BaselineDir, "Directory LoadBaseline may load results from (empty disables it)")
+	importPath := flag.String("import", config.Import.Path, "Results to serve with the import provider")
+	importFormat := flag.String("import_format", config.Import.Format, "Format of the imported results: ci, results, test262-harness, test262.fyi or csv")
+	live := flag.Bool("live", config.Live, "Overlay local run results over the provider's results")
+	refreshInterval := flag.String("refresh_interval", config.RefreshInterval, "Periodically refresh the provider's results, e.g. 15m (empty disables it)")
+	cacheDir := flag.String("cache_dir", config.CacheDir, "Directory HTTP fetches are cached in (empty disables the cache)")
+	cacheTTL := flag.String("cache_ttl", config.CacheTTL, "Serve cached HTTP fetches without revalidating them for this long, e.g. 10m")
+	offline := flag.Bool("offline", config.Offline, "Serve HTTP fetches only from the cache")
+	test262Repo := flag.String("test262_repo", config.Sources.Test262.Repo, "GitHub repository test262 code is fetched from")
+	test262Ref := flag.String("test262_ref", config.Sources.Test262.Ref, "Branch, tag or commit test262 code is fetched at")
+	resultsRepo := flag.String("results_repo", config.Sources.Results.Repo, "GitHub repository (yavashark-data layout) CI results are fetched from")
+	resultsRef := flag.String("results_ref", config.Sources.Results.Ref, "Branch, tag or commit CI results are fetched at")
+	pinTest262 := flag.Bool("pin_test262", config.Sources.PinTest262, "Fetch test262 code at the commit the results were produced against")
+	test262CommitPath := flag.String("test262_commit_path", config.Sources.Test262CommitPath, "File of the results holding the test262 commit they were produced against")
+	importEngine := flag.String("import_engine", config.Import.Engine, "Engine to import for formats containing multiple engines")
 
 	flag.Parse()
 
@@ -68,18 +264,81 @@ func LoadConfig() *Config {
 
 	flag.Visit(func(f *flag.Flag) {
 		switch f.Name {
+		case "transport":
+			config.Transport = *transport
+		case "listen":
+			config.Listen = *listen
+		case "base_path":
+			config.BasePath = *basePath
+		case "token_file":
+			config.Auth.TokenFile = *tokenFile
+		case "read_only":
+			config.ReadOnly = *readOnly
+		case "tls_cert":
+			config.TLSCert = *tlsCert
+		case "tls_key":
+			config.TLSKey = *tlsKey
 		case "repo":
 			config.RepoPath = *repoPath
 		case "workers":
 			config.Workers = *workers
 		case "test_root":
 			config.TestRootDir = *testRootDir
+		case "output_dir":
+			config.OutputDir = *outputDir
+		case "provider":
+			config.Provider = *provider
+		case "data_dir":
+			config.DataDir = *dataDir
+		case "baseline_dir":
+			config.BaselineDir = *baselineDir
+		case "import":
+			config.Import.Path = *importPath
+		case "import_format":
+			config.Import.Format = *importFormat
+		case "import_engine":
+			config.Import.Engine = *importEngine
+		case "live":
+			config.Live = *live
+		case "refresh_interval":
+			config.RefreshInterval = *refreshInterval
+		case "cache_dir":
+			config.CacheDir = *cacheDir
+		case "cache_ttl":
+			config.CacheTTL = *cacheTTL
+		case "offline":
+			config.Offline = *offline
+		case "test262_repo":
+			config.Sources.Test262.Repo = *test262Repo
+		case "test262_ref":
+			config.Sources.Test262.Ref = *test262Ref
+		case "results_repo":
+			config.Sources.Results.Repo = *resultsRepo
+		case "results_ref":
+			config.Sources.Results.Ref = *resultsRef
+		case "pin_test262":
+			config.Sources.PinTest262 = *pinTest262
+		case "test262_commit_path":
+			config.Sources.Test262CommitPath = *test262CommitPath
 		}
 	})
 
 	return config
 }
 
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "mcp262")
+}
+
+func parseBool(s string) bool {
+	return s == "1" || strings.EqualFold(s, "true")
+}
+
 func loadConfigFile(filename string, config *Config) error {
 	_, err := toml.DecodeFile(filename, config)
 	return err
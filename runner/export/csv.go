@@ -0,0 +1,51 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"io"
+	"strconv"
+
+	"github.com/Sharktheone/mcp262/runner/results"
+)
+
+var CSVHeader = []string{"path", "status", "duration_ms", "memory_kb", "message_hash"}
+
+// WriteCSV writes one row per result. The message is replaced by a short hash so that
+// identical failures can be grouped without bloating the file.
+func WriteCSV(w io.Writer, res []results.Result) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(CSVHeader); err != nil {
+		return err
+	}
+
+	for _, r := range res {
+		err := cw.Write([]string{
+			r.Path,
+			r.Status.String(),
+			strconv.FormatInt(r.Duration.Milliseconds(), 10),
+			strconv.FormatUint(r.MemoryKB, 10),
+			messageHash(r.Msg),
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func messageHash(msg string) string {
+	if msg == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(msg))
+
+	return hex.EncodeToString(sum[:8])
+}
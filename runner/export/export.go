@@ -0,0 +1,60 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Sharktheone/mcp262/runner/results"
+)
+
+type Format string
+
+const (
+	JUNIT Format = "junit"
+	TAP   Format = "tap"
+	CSV   Format = "csv"
+)
+
+func ParseFormat(f string) (Format, error) {
+	switch strings.ToLower(f) {
+	case "junit", "xml":
+		return JUNIT, nil
+	case "tap":
+		return TAP, nil
+	case "csv":
+		return CSV, nil
+	default:
+		return "", fmt.Errorf("unknown export format: %s", f)
+	}
+}
+
+func (f Format) Extension() string {
+	switch f {
+	case JUNIT:
+		return "xml"
+	default:
+		return string(f)
+	}
+}
+
+func Write(w io.Writer, format Format, res []results.Result) error {
+	sorted := make([]results.Result, len(res))
+	copy(sorted, res)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	switch format {
+	case JUNIT:
+		return WriteJUnit(w, sorted)
+	case TAP:
+		return WriteTAP(w, sorted)
+	case CSV:
+		return WriteCSV(w, sorted)
+	default:
+		return fmt.Errorf("unknown export format: %s", format)
+	}
+}
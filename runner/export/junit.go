@@ -0,0 +1,132 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+
+	"github.com/Sharktheone/mcp262/runner/results"
+	"github.com/Sharktheone/mcp262/runner/status"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// WriteJUnit writes the results as JUnit XML, with one testsuite per test directory.
+func WriteJUnit(w io.Writer, res []results.Result) error {
+	suites := make(map[string]*junitTestSuite)
+	durations := make(map[string]float64)
+
+	root := junitTestSuites{Name: "test262"}
+	var total float64
+
+	for _, r := range res {
+		dir := path.Dir(r.Path)
+
+		suite, ok := suites[dir]
+		if !ok {
+			suite = &junitTestSuite{Name: dir}
+			suites[dir] = suite
+		}
+
+		tc := junitTestCase{
+			Name:      path.Base(r.Path),
+			ClassName: dir,
+			Time:      seconds(r.Duration.Seconds()),
+		}
+
+		switch r.Status {
+		case status.PASS:
+		case status.SKIP:
+			tc.Skipped = &junitSkipped{Message: firstLine(r.Msg)}
+			suite.Skipped++
+		case status.FAIL:
+			tc.Failure = &junitProblem{Message: firstLine(r.Msg), Type: r.Status.String(), Body: r.Msg}
+			suite.Failures++
+		default:
+			tc.Error = &junitProblem{Message: firstLine(r.Msg), Type: r.Status.String(), Body: r.Msg}
+			suite.Errors++
+		}
+
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+		durations[dir] += r.Duration.Seconds()
+		total += r.Duration.Seconds()
+	}
+
+	names := make([]string, 0, len(suites))
+	for name := range suites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		suite := suites[name]
+		suite.Time = seconds(durations[name])
+
+		root.Tests += suite.Tests
+		root.Failures += suite.Failures
+		root.Errors += suite.Errors
+		root.Skipped += suite.Skipped
+		root.Suites = append(root.Suites, *suite)
+	}
+
+	root.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
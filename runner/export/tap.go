@@ -0,0 +1,54 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Sharktheone/mcp262/runner/results"
+	"github.com/Sharktheone/mcp262/runner/status"
+)
+
+// WriteTAP writes the results as a TAP version 13 stream with a YAML diagnostic block for every non-passing test.
+func WriteTAP(w io.Writer, res []results.Result) error {
+	bw := bufio.NewWriter(w)
+
+	_, _ = fmt.Fprintln(bw, "TAP version 13")
+	_, _ = fmt.Fprintf(bw, "1..%d\n", len(res))
+
+	for i, r := range res {
+		switch r.Status {
+		case status.PASS:
+			_, _ = fmt.Fprintf(bw, "ok %d - %s\n", i+1, r.Path)
+			continue
+		case status.SKIP:
+			_, _ = fmt.Fprintf(bw, "ok %d - %s # SKIP %s\n", i+1, r.Path, firstLine(r.Msg))
+			continue
+		}
+
+		_, _ = fmt.Fprintf(bw, "not ok %d - %s\n", i+1, r.Path)
+		_, _ = fmt.Fprintln(bw, "  ---")
+		_, _ = fmt.Fprintf(bw, "  status: %s\n", r.Status)
+		_, _ = fmt.Fprintf(bw, "  duration_ms: %d\n", r.Duration.Milliseconds())
+		_, _ = fmt.Fprintf(bw, "  memory_kb: %d\n", r.MemoryKB)
+		if r.Msg != "" {
+			_, _ = fmt.Fprintln(bw, "  message: |")
+			for _, line := range strings.Split(strings.TrimRight(r.Msg, "\n"), "\n") {
+				_, _ = fmt.Fprintf(bw, "    %s\n", line)
+			}
+		}
+		_, _ = fmt.Fprintln(bw, "  ...")
+	}
+
+	return bw.Flush()
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i != -1 {
+		return s[:i]
+	}
+
+	return s
+}
@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Sharktheone/mcp262/runner/results"
+	"github.com/Sharktheone/mcp262/runner/status"
+)
+
+// LoadCSV reads a "path,status" csv file. A header row and any additional columns are ignored.
+func LoadCSV(r io.Reader) (*results.TestResults, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+
+	res := make([]results.Result, 0)
+
+	line := 0
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line++
+
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected path,status", line)
+		}
+
+		if line == 1 && strings.EqualFold(record[0], "path") {
+			continue
+		}
+
+		s, err := ParseStatus(record[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		res = append(res, results.Result{
+			Status: s,
+			Path:   normalizeTestPath(record[0]),
+		})
+	}
+
+	return results.FromResults(res), nil
+}
+
+// ParseStatus accepts full status names, CI short statuses and common pass/fail spellings.
+func ParseStatus(s string) (status.Status, error) {
+	s = strings.TrimSpace(s)
+
+	if st, err := status.ParseStatus(strings.ToUpper(s)); err == nil {
+		return st, nil
+	}
+
+	if ci := status.CIStatus(s); ci.IsValid() {
+		return ci.ToStatus(), nil
+	}
+
+	switch strings.ToLower(s) {
+	case "pass", "passed", "ok", "true", "1":
+		return status.PASS, nil
+	case "fail", "failed", "failure", "false", "0":
+		return status.FAIL, nil
+	case "skip", "skipped":
+		return status.SKIP, nil
+	case "timeout", "timed out":
+		return status.TIMEOUT, nil
+	case "crash", "crashed", "error":
+		return status.CRASH, nil
+	}
+
+	return status.CRASH, errors.New("unknown status")
+}
@@ -0,0 +1,135 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Sharktheone/mcp262/runner/results"
+	"github.com/Sharktheone/mcp262/runner/status"
+)
+
+// LoadFyi imports the results of a single engine from a test262.fyi data directory.
+//
+// Two layouts are understood:
+//   - per-directory files (e.g. built-ins/Array.json) with a "files" object that maps
+//     each test in that directory to its per-engine results ({"length.js": {"v8": true, ...}})
+//   - a per-engine file (e.g. v8.json) that maps test paths to results ({"built-ins/Array/length.js": true})
+//
+// A result may be a bool, a number (non-zero means pass) or a status string.
+func LoadFyi(dir string, engine string) (*results.TestResults, error) {
+	if engine == "" {
+		return nil, errors.New("test262.fyi import requires an engine")
+	}
+
+	res := make([]results.Result, 0)
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || filepath.Ext(p) != ".json" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(strings.TrimSuffix(rel, ".json"))
+
+		contents, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		var data struct {
+			Files map[string]map[string]json.RawMessage `json:"files"`
+		}
+
+		if err := json.Unmarshal(contents, &data); err == nil && data.Files != nil {
+			for name, engines := range data.Files {
+				raw, ok := engines[engine]
+				if !ok {
+					continue
+				}
+
+				s, err := parseFyiResult(raw)
+				if err != nil {
+					return fmt.Errorf("%s: %w", p, err)
+				}
+
+				testPath := name
+				if !strings.Contains(name, "/") && rel != "index" {
+					testPath = path.Join(rel, name)
+				}
+
+				res = append(res, results.Result{Status: s, Path: normalizeTestPath(testPath)})
+			}
+
+			return nil
+		}
+
+		if path.Base(rel) != engine {
+			return nil
+		}
+
+		var perEngine map[string]json.RawMessage
+		if err := json.Unmarshal(contents, &perEngine); err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+
+		for testPath, raw := range perEngine {
+			s, err := parseFyiResult(raw)
+			if err != nil {
+				continue
+			}
+
+			res = append(res, results.Result{Status: s, Path: normalizeTestPath(testPath)})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no results for engine %s in %s", engine, dir)
+	}
+
+	return results.FromResults(res), nil
+}
+
+func parseFyiResult(raw json.RawMessage) (status.Status, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		if b {
+			return status.PASS, nil
+		}
+		return status.FAIL, nil
+	}
+
+	var n float64
+	if err := json.Unmarshal(raw, &n); err == nil {
+		if n != 0 {
+			return status.PASS, nil
+		}
+		return status.FAIL, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return ParseStatus(s)
+	}
+
+	return status.CRASH, errors.New("unsupported result")
+}
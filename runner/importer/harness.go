@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/Sharktheone/mcp262/runner/results"
+	"github.com/Sharktheone/mcp262/runner/status"
+)
+
+type harnessTest struct {
+	File     string `json:"file"`
+	Scenario string `json:"scenario"`
+	Result   struct {
+		Pass    bool   `json:"pass"`
+		Message string `json:"message"`
+	} `json:"result"`
+	RawResult *struct {
+		Stdout  string `json:"stdout"`
+		Stderr  string `json:"stderr"`
+		Timeout bool   `json:"timeout"`
+	} `json:"rawResult"`
+}
+
+// LoadHarnessJSON reads the array written by `test262-harness --reporter=json`.
+// Tests that ran in multiple scenarios (strict / non-strict) only pass if every scenario passed.
+func LoadHarnessJSON(r io.Reader) (*results.TestResults, error) {
+	var tests []harnessTest
+	if err := json.NewDecoder(r).Decode(&tests); err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(tests))
+	res := make([]results.Result, 0, len(tests))
+
+	for _, t := range tests {
+		p := normalizeTestPath(t.File)
+
+		r := results.Result{
+			Status: status.PASS,
+			Msg:    t.Result.Message,
+			Path:   p,
+		}
+
+		if !t.Result.Pass {
+			r.Status = status.FAIL
+			if t.RawResult != nil && t.RawResult.Timeout || strings.Contains(strings.ToLower(t.Result.Message), "timeout") {
+				r.Status = status.TIMEOUT
+			}
+
+			if t.Scenario != "" {
+				r.Msg = t.Scenario + ": " + r.Msg
+			}
+		}
+
+		if i, ok := index[p]; ok {
+			if res[i].Status == status.PASS && r.Status != status.PASS {
+				res[i] = r
+			}
+			continue
+		}
+
+		index[p] = len(res)
+		res = append(res, r)
+	}
+
+	return results.FromResults(res), nil
+}
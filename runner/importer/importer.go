@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Sharktheone/mcp262/runner/ci"
+	"github.com/Sharktheone/mcp262/runner/results"
+)
+
+type Format string
+
+const (
+	// CI is the compact [{s,p}] format written by WriteCIResultsPath.
+	CI Format = "ci"
+	// RESULTS is the full results.json written by the runner.
+	RESULTS Format = "results"
+	// HARNESS is the output of `test262-harness --reporter=json`.
+	HARNESS Format = "test262-harness"
+	// FYI is a checkout of the test262.fyi data directory.
+	FYI Format = "test262.fyi"
+	// CSV is a generic "path,status" csv file.
+	CSV Format = "csv"
+)
+
+func ParseFormat(f string) (Format, error) {
+	switch strings.ToLower(f) {
+	case "ci", "":
+		return CI, nil
+	case "results":
+		return RESULTS, nil
+	case "test262-harness", "harness":
+		return HARNESS, nil
+	case "test262.fyi", "fyi":
+		return FYI, nil
+	case "csv":
+		return CSV, nil
+	default:
+		return "", fmt.Errorf("unknown import format: %s", f)
+	}
+}
+
+// Load imports results from path. engine selects the engine for formats that contain results of multiple engines.
+func Load(format Format, path string, engine string) (*results.TestResults, error) {
+	switch format {
+	case CI:
+		tr, err := ci.LoadPrevCi(path)
+		if err == nil && tr == nil {
+			err = fmt.Errorf("%s: %w", path, os.ErrNotExist)
+		}
+		return tr, err
+	case RESULTS:
+		res, err := results.LoadResultsPath(path)
+		if err == nil && res == nil {
+			err = fmt.Errorf("%s: %w", path, os.ErrNotExist)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return results.FromResults(res), nil
+	case HARNESS:
+		return loadFile(path, LoadHarnessJSON)
+	case FYI:
+		return LoadFyi(path, engine)
+	case CSV:
+		return loadFile(path, LoadCSV)
+	default:
+		return nil, fmt.Errorf("unknown import format: %s", format)
+	}
+}
+
+func loadFile(path string, load func(io.Reader) (*results.TestResults, error)) (*results.TestResults, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return load(f)
+}
+
+// normalizeTestPath strips everything up to and including the test262 "test/" directory.
+func normalizeTestPath(p string) string {
+	p = strings.ReplaceAll(p, "\\", "/")
+
+	if i := strings.LastIndex(p, "/test262/test/"); i != -1 {
+		return p[i+len("/test262/test/"):]
+	}
+
+	p = strings.TrimPrefix(p, "./")
+	p = strings.TrimPrefix(p, "/")
+	p = strings.TrimPrefix(p, "test262/")
+	p = strings.TrimPrefix(p, "test/")
+
+	return p
+}
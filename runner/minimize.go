@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Sharktheone/mcp262/provider"
+	"github.com/Sharktheone/mcp262/runner/minimize"
+	"github.com/Sharktheone/mcp262/runner/overlay"
+	"github.com/Sharktheone/mcp262/runner/rebuild"
+)
+
+func (r *Runner) MinimizeTest(testPath string, rebuildEngine bool, maxRuns int) (provider.MinimizedTest, error) {
+	return r.minimizeTest(testPath, rebuildEngine, maxRuns, provider.Overlay{})
+}
+
+// minimizeTest reduces the test as it is in the overlay, or on disk if it isn't edited. The candidates are
+// staged in a temporary tree with the edits, results are not recorded.
+func (r *Runner) minimizeTest(testPath string, rebuildEngine bool, maxRuns int, o provider.Overlay) (provider.MinimizedTest, error) {
+	code, ok := o.Tests[testPath]
+	if !ok {
+		b, err := os.ReadFile(filepath.Join(r.testRoot, testPath))
+		if err != nil {
+			return provider.MinimizedTest{}, err
+		}
+		code = string(b)
+	}
+
+	tree, err := overlay.New(r.testRoot, o.Tests, o.Harness)
+	if err != nil {
+		return provider.MinimizedTest{}, err
+	}
+
+	defer tree.Close()
+
+	loc, cancel, err := rebuild.RebuildEngine(r.repoRoot, 1, rebuildEngine)
+	if err != nil {
+		return provider.MinimizedTest{}, wrapBuildError(err)
+	}
+
+	cancel()
+
+	res, err := minimize.New(tree, testPath, r.repoRoot, r.workers, loc, maxRuns).Minimize(code)
+	if err != nil {
+		return provider.MinimizedTest{}, err
+	}
+
+	return provider.MinimizedTest{
+		TestPath:      testPath,
+		Status:        res.Status.String(),
+		Signature:     res.Signature,
+		Code:          res.Code,
+		OriginalLines: strings.Count(code, "\n"),
+		Lines:         strings.Count(res.Code, "\n"),
+		Statements:    res.Units,
+		Kept:          res.Kept,
+		Runs:          res.Runs,
+		Complete:      res.Complete,
+	}, nil
+}
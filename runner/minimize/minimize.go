@@ -0,0 +1,242 @@
+package minimize
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/Sharktheone/mcp262/runner/overlay"
+	"github.com/Sharktheone/mcp262/runner/rebuild"
+	"github.com/Sharktheone/mcp262/runner/results"
+	"github.com/Sharktheone/mcp262/runner/status"
+	"github.com/Sharktheone/mcp262/runner/worker"
+)
+
+const DEFAULT_MAX_RUNS = 500
+
+// MAX_DEPTH is how many levels of nested blocks statements are removed from, 1 only removes top-level statements.
+const MAX_DEPTH = 3
+
+type Result struct {
+	Code      string
+	Status    status.Status
+	Signature string
+	// Units and Kept count the statements at all levels that were tried and kept
+	Units int
+	Kept  int
+	Runs  int
+	// Complete is false if the run budget was used up before no statement could be removed anymore
+	Complete bool
+}
+
+// Minimizer reduces a failing test with delta debugging: statements are removed in chunks, halving the chunks
+// until single statements, and a reduction is kept while the failure signature stays the same.
+// Candidates are staged next to the test in the tree (so relative module imports resolve) and run on the workers.
+type Minimizer struct {
+	tree     *overlay.Tree
+	testPath string
+	repoRoot string
+	workers  int
+	loc      *rebuild.EngineLocation
+
+	maxRuns   int
+	runs      int
+	complete  bool
+	signature string
+	units     int
+	kept      int
+}
+
+func New(tree *overlay.Tree, testPath string, repoRoot string, workers int, loc *rebuild.EngineLocation, maxRuns int) *Minimizer {
+	if maxRuns <= 0 {
+		maxRuns = DEFAULT_MAX_RUNS
+	}
+
+	return &Minimizer{
+		tree:     tree,
+		testPath: testPath,
+		repoRoot: repoRoot,
+		workers:  max(workers, 1),
+		loc:      loc,
+		maxRuns:  maxRuns,
+		complete: true,
+	}
+}
+
+func (m *Minimizer) Minimize(code string) (*Result, error) {
+	orig := m.run([]string{code})[0]
+	if orig.Status == status.PASS || orig.Status == status.SKIP {
+		return nil, fmt.Errorf("test doesn't fail (%s), nothing to minimize", orig.Status)
+	}
+
+	if orig.Status == status.RUNNER_ERROR {
+		return nil, errors.New("the engine could not be started: " + orig.Msg)
+	}
+
+	m.signature = m.signatureOf(orig, 0)
+
+	header, body := Header(code)
+	body, space := trailingSpace(body)
+	units := m.reduce(header, Split(body), space, 1)
+
+	reduced := header + strings.Join(units, "") + space
+
+	return &Result{
+		Code:      reduced,
+		Status:    orig.Status,
+		Signature: m.signature,
+		Units:     m.units,
+		Kept:      m.kept,
+		Runs:      m.runs,
+		Complete:  m.complete,
+	}, nil
+}
+
+// reduce minimizes units between a fixed prefix and suffix, then the statements in the blocks of the kept units.
+func (m *Minimizer) reduce(prefix string, units []string, suffix string, depth int) []string {
+	m.units += len(units)
+	units = m.ddmin(prefix, units, suffix)
+	m.kept += len(units)
+
+	if depth >= MAX_DEPTH {
+		return units
+	}
+
+	for k := range units {
+		head, body, tail, ok := Body(units[k])
+		if !ok {
+			continue
+		}
+
+		inner := Split(body)
+		if len(inner) == 0 {
+			continue
+		}
+
+		p := prefix + strings.Join(units[:k], "") + head
+		s := tail + strings.Join(units[k+1:], "") + suffix
+
+		units[k] = head + strings.Join(m.reduce(p, inner, s, depth+1), "") + tail
+	}
+
+	return units
+}
+
+func (m *Minimizer) ddmin(prefix string, units []string, suffix string) []string {
+	n := min(2, len(units))
+
+	for len(units) > 0 {
+		if m.runs >= m.maxRuns {
+			m.complete = false
+			break
+		}
+
+		var candidates [][]string
+		for i := 0; i < n; i++ {
+			from, to := i*len(units)/n, (i+1)*len(units)/n
+
+			c := make([]string, 0, len(units)-(to-from))
+			c = append(c, units[:from]...)
+			c = append(c, units[to:]...)
+			candidates = append(candidates, c)
+		}
+
+		if budget := m.maxRuns - m.runs; len(candidates) > budget {
+			candidates = candidates[:budget]
+			m.complete = false
+		}
+
+		codes := make([]string, len(candidates))
+		for i, c := range candidates {
+			codes[i] = prefix + strings.Join(c, "") + suffix
+		}
+
+		found := -1
+		for i, res := range m.run(codes) {
+			if m.signatureOf(res, i) == m.signature {
+				found = i
+				break
+			}
+		}
+
+		if found >= 0 {
+			units = candidates[found]
+			n = min(max(n-1, 2), len(units))
+			continue
+		}
+
+		if n >= len(units) {
+			break
+		}
+
+		n = min(2*n, len(units))
+	}
+
+	return units
+}
+
+// run stages the candidates and runs them on the workers, results are in the order of the candidates.
+func (m *Minimizer) run(codes []string) []results.Result {
+	out := make([]results.Result, len(codes))
+	index := make(map[string]int, len(codes))
+
+	jobs := make(chan worker.Job, len(codes))
+	for i, code := range codes {
+		p := m.candidatePath(i)
+		if err := m.tree.Write(p, code); err != nil {
+			out[i] = results.Result{Status: status.RUNNER_ERROR, Msg: err.Error(), Path: p}
+			continue
+		}
+
+		index[p] = i
+		jobs <- worker.Job{FullPath: m.tree.Path(p), RelativePath: p}
+	}
+	close(jobs)
+
+	m.runs += len(index)
+
+	resultsChan := make(chan results.Result, len(index))
+	wg := &sync.WaitGroup{}
+
+	workers := min(m.workers, len(index))
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go worker.Worker(i, m.repoRoot, jobs, resultsChan, wg, m.loc)
+	}
+
+	wg.Wait()
+	close(resultsChan)
+
+	for res := range resultsChan {
+		out[index[res.Path]] = res
+	}
+
+	return out
+}
+
+// candidatePath is next to the test, so imports relative to it resolve.
+func (m *Minimizer) candidatePath(i int) string {
+	return strings.TrimSuffix(m.testPath, ".js") + fmt.Sprintf(".mcp262-min-%d.js", i)
+}
+
+// signatureOf identifies a failure by its status and panic location, or the first line of the output for failures
+// without one. Paths of the candidate are replaced by the test path, as engines tend to print them.
+func (m *Minimizer) signatureOf(res results.Result, candidate int) string {
+	sig := res.Status.String()
+
+	switch {
+	case res.Panic != nil:
+		return sig + " " + res.Panic.Key()
+	case res.Status == status.FAIL || res.Status == status.CRASH:
+		msg := strings.ReplaceAll(res.Msg, m.tree.Path(m.candidatePath(candidate)), m.testPath)
+		msg = strings.ReplaceAll(msg, m.candidatePath(candidate), m.testPath)
+		msg = strings.ReplaceAll(msg, path.Base(m.candidatePath(candidate)), path.Base(m.testPath))
+
+		line, _, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+		return sig + " " + line
+	default:
+		return sig
+	}
+}
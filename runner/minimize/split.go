@@ -0,0 +1,373 @@
+package minimize
+
+import (
+	"strings"
+)
+
+const FRONTMATTER_END = "---*/"
+
+type token struct {
+	start int
+	end   int
+	// kind is 'w' for words (identifiers, keywords, numbers), 'p' for punctuation and 's' for strings, templates and regexes
+	kind byte
+	// nl is set if there is a line break between the previous token and this one
+	nl bool
+}
+
+func (t token) text(src string) string {
+	return src[t.start:t.end]
+}
+
+// words after which a / starts a regex and not a division
+var regexAfter = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true, "new": true, "delete": true,
+	"void": true, "throw": true, "case": true, "do": true, "else": true, "yield": true, "await": true,
+}
+
+// words that continue a statement on the next line
+var continuation = map[string]bool{
+	"else": true, "catch": true, "finally": true, "instanceof": true, "in": true, "of": true,
+}
+
+// control statements whose body may start on the next line
+var control = map[string]bool{
+	"if": true, "for": true, "while": true, "with": true,
+}
+
+// Header splits off everything up to the end of the frontmatter, which is kept in every candidate.
+func Header(code string) (string, string) {
+	if !strings.Contains(code, "/*---") {
+		return "", code
+	}
+
+	i := strings.Index(code, FRONTMATTER_END)
+	if i < 0 {
+		return "", code
+	}
+
+	i += len(FRONTMATTER_END)
+	if j := strings.IndexByte(code[i:], '\n'); j >= 0 && strings.TrimSpace(code[i:i+j]) == "" {
+		i += j + 1
+	}
+
+	return code[:i], code[i:]
+}
+
+// Split splits source into its top-level statements, a statement with blocks (functions, loops, try, ...) is one unit.
+// Each unit keeps the whitespace and comments before it, so joining the units gives back the source.
+// The split is a heuristic without a full parser: a wrong split only makes candidates that don't keep the failure.
+func Split(src string) []string {
+	tokens := tokenize(src)
+	if len(tokens) == 0 {
+		if strings.TrimSpace(src) == "" {
+			return nil
+		}
+		return []string{src}
+	}
+
+	var units []string
+	start := 0
+	depth := 0
+	unitStart := 0
+	// pendingBody is set after the head of a control statement, its body may follow on the next line
+	pendingBody := false
+	var parens []bool
+
+	for i, t := range tokens {
+		text := t.text(src)
+		boundary := false
+
+		if t.kind == 'p' {
+			switch text {
+			case "(":
+				parens = append(parens, i > 0 && tokens[i-1].kind == 'w' && control[tokens[i-1].text(src)])
+				depth++
+			case "[", "{":
+				depth++
+			case ")":
+				depth--
+				if len(parens) > 0 {
+					head := parens[len(parens)-1]
+					parens = parens[:len(parens)-1]
+					if head && depth == 0 {
+						pendingBody = true
+						continue
+					}
+				}
+			case "]", "}":
+				depth--
+			}
+		} else if t.kind == 'w' && depth == 0 && (text == "else" || text == "do") {
+			pendingBody = true
+			continue
+		}
+
+		if depth != 0 {
+			continue
+		}
+
+		var next *token
+		if i+1 < len(tokens) {
+			next = &tokens[i+1]
+		}
+
+		switch {
+		case next == nil:
+			boundary = true
+		case text == ";":
+			// if (x) foo(); else bar(); and do foo(); while (x); are one statement
+			head := tokens[unitStart].text(src)
+			boundary = !(next.kind == 'w' && (head == "if" && next.text(src) == "else" || head == "do" && next.text(src) == "while"))
+		case text == "}":
+			boundary = !continues(src, *next, false)
+		case pendingBody:
+		case next.nl && (t.kind != 'p' || text == ")" || text == "]"):
+			boundary = !control[tokens[unitStart].text(src)] && !continues(src, *next, true)
+		}
+
+		pendingBody = false
+
+		if boundary {
+			end := t.end
+			if next == nil {
+				end = len(src)
+			}
+
+			units = append(units, src[start:end])
+			start = end
+			unitStart = i + 1
+		}
+	}
+
+	if start < len(src) {
+		if len(units) == 0 {
+			return []string{src}
+		}
+		units[len(units)-1] += src[start:]
+	}
+
+	return units
+}
+
+// continues reports whether next continues the statement before it. After a line break only words and
+// strings start a new statement, after a block anything but punctuation and the keywords continuing it does.
+func continues(src string, next token, afterNewline bool) bool {
+	text := next.text(src)
+
+	switch next.kind {
+	case 'w':
+		return continuation[text] || text == "while" && !afterNewline
+	case 's':
+		return text[0] == '`' || text[0] == '/'
+	default:
+		return !(text == "{" && !afterNewline)
+	}
+}
+
+// Body splits a unit at its first top-level block into head + "{", the code of the block and "}" + tail.
+func Body(unit string) (string, string, string, bool) {
+	tokens := tokenize(unit)
+
+	depth := 0
+	open := -1
+
+	for _, t := range tokens {
+		if t.kind != 'p' {
+			continue
+		}
+
+		switch unit[t.start] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case '{':
+			if depth == 0 && open < 0 {
+				open = t.end
+			}
+			depth++
+		case '}':
+			depth--
+			if depth == 0 && open >= 0 {
+				body, space := trailingSpace(unit[open:t.start])
+				return unit[:open], body, space + unit[t.start:], true
+			}
+		}
+	}
+
+	return "", "", "", false
+}
+
+// trailingSpace splits off the whitespace at the end of src, which stays when the last statement is removed.
+func trailingSpace(src string) (string, string) {
+	trimmed := strings.TrimRight(src, " \t\r\n")
+	return trimmed, src[len(trimmed):]
+}
+
+func tokenize(src string) []token {
+	var tokens []token
+	nl := false
+
+	for i := 0; i < len(src); {
+		c := src[i]
+
+		switch {
+		case c == '\n':
+			nl = true
+			i++
+			continue
+
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			continue
+
+		case strings.HasPrefix(src[i:], "//"):
+			j := strings.IndexByte(src[i:], '\n')
+			if j < 0 {
+				return tokens
+			}
+			i += j
+			continue
+
+		case strings.HasPrefix(src[i:], "/*"):
+			j := strings.Index(src[i+2:], "*/")
+			if j < 0 {
+				return tokens
+			}
+			if strings.Contains(src[i:i+2+j], "\n") {
+				nl = true
+			}
+			i += j + 4
+			continue
+		}
+
+		t := token{start: i, nl: nl}
+		nl = false
+
+		switch {
+		case c == '"' || c == '\'':
+			t.kind, t.end = 's', skipString(src, i)
+
+		case c == '`':
+			t.kind, t.end = 's', skipTemplate(src, i)
+
+		case c == '/' && regexAllowed(src, tokens):
+			t.kind, t.end = 's', skipRegex(src, i)
+
+		case isWord(c):
+			j := i + 1
+			for j < len(src) && isWord(src[j]) {
+				j++
+			}
+			t.kind, t.end = 'w', j
+
+		default:
+			t.kind, t.end = 'p', i+1
+		}
+
+		tokens = append(tokens, t)
+		i = t.end
+	}
+
+	return tokens
+}
+
+func regexAllowed(src string, tokens []token) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+
+	prev := tokens[len(tokens)-1]
+	text := prev.text(src)
+
+	switch prev.kind {
+	case 'w':
+		return regexAfter[text]
+	case 's':
+		return false
+	default:
+		return text != ")" && text != "]"
+	}
+}
+
+func skipString(src string, i int) int {
+	q := src[i]
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case q, '\n':
+			return j + 1
+		}
+	}
+
+	return len(src)
+}
+
+func skipTemplate(src string, i int) int {
+	for j := i + 1; j < len(src); j++ {
+		switch {
+		case src[j] == '\\':
+			j++
+		case src[j] == '`':
+			return j + 1
+		case strings.HasPrefix(src[j:], "${"):
+			j = skipSubstitution(src, j+2) - 1
+		}
+	}
+
+	return len(src)
+}
+
+// skipSubstitution returns the end of the ${} substitution whose code starts at i.
+func skipSubstitution(src string, i int) int {
+	depth := 0
+	for _, t := range tokenize(src[i:]) {
+		if t.kind != 'p' {
+			continue
+		}
+
+		switch src[i+t.start] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i + t.end
+			}
+			depth--
+		}
+	}
+
+	return len(src)
+}
+
+func skipRegex(src string, i int) int {
+	class := false
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '[':
+			class = true
+		case ']':
+			class = false
+		case '\n':
+			return j
+		case '/':
+			if !class {
+				j++
+				for j < len(src) && isWord(src[j]) {
+					j++
+				}
+				return j
+			}
+		}
+	}
+
+	return len(src)
+}
+
+func isWord(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
@@ -0,0 +1,178 @@
+package minimize
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "statements",
+			src:  "var a = 1;\nvar b = 2;\nfoo(a, b);\n",
+			want: []string{"var a = 1;", "\nvar b = 2;", "\nfoo(a, b);\n"},
+		},
+		{
+			name: "without semicolons",
+			src:  "let a = 1\nlet b = a\nfoo()\n",
+			want: []string{"let a = 1", "\nlet b = a", "\nfoo()\n"},
+		},
+		{
+			name: "blocks",
+			src:  "function f() {\n  return 1;\n}\nfor (let i = 0; i < 3; i++) {\n  f();\n}\n",
+			want: []string{"function f() {\n  return 1;\n}", "\nfor (let i = 0; i < 3; i++) {\n  f();\n}\n"},
+		},
+		{
+			name: "if else without blocks",
+			src:  "if (x) foo(); else bar();\nbaz();\n",
+			want: []string{"if (x) foo(); else bar();", "\nbaz();\n"},
+		},
+		{
+			name: "if else on separate lines",
+			src:  "if (x)\n  foo();\nelse\n  bar();\nbaz();\n",
+			want: []string{"if (x)\n  foo();\nelse\n  bar();", "\nbaz();\n"},
+		},
+		{
+			name: "if else blocks",
+			src:  "if (x) {\n  foo();\n} else if (y) {\n  bar();\n} else {\n  baz();\n}\nqux();\n",
+			want: []string{"if (x) {\n  foo();\n} else if (y) {\n  bar();\n} else {\n  baz();\n}", "\nqux();\n"},
+		},
+		{
+			name: "do while",
+			src:  "do foo(); while (x);\nbar();\n",
+			want: []string{"do foo(); while (x);", "\nbar();\n"},
+		},
+		{
+			name: "try catch finally",
+			src:  "try {\n  foo();\n} catch (e) {\n  bar();\n} finally {\n  baz();\n}\nqux();\n",
+			want: []string{"try {\n  foo();\n} catch (e) {\n  bar();\n} finally {\n  baz();\n}", "\nqux();\n"},
+		},
+		{
+			name: "regex with braces and semicolons",
+			src:  "var re = /[;}]{2}/g;\nre.test(s);\n",
+			want: []string{"var re = /[;}]{2}/g;", "\nre.test(s);\n"},
+		},
+		{
+			name: "division is not a regex",
+			src:  "var a = b / c / d;\nfoo();\n",
+			want: []string{"var a = b / c / d;", "\nfoo();\n"},
+		},
+		{
+			name: "template with substitution",
+			src:  "var s = `a;${ {b: 1}.b }\n}`;\nfoo(s);\n",
+			want: []string{"var s = `a;${ {b: 1}.b }\n}`;", "\nfoo(s);\n"},
+		},
+		{
+			name: "strings and comments",
+			src:  "var s = \"};\"; // };\n/* ; } */ foo('{');\n",
+			want: []string{"var s = \"};\";", " // };\n/* ; } */ foo('{');\n"},
+		},
+		{
+			name: "continued expression",
+			src:  "var a = b\n  + c;\nfoo();\n",
+			want: []string{"var a = b\n  + c;", "\nfoo();\n"},
+		},
+		{
+			name: "empty",
+			src:  "\n  \n",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Split(tt.src)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split(%q)\n got %q\nwant %q", tt.src, got, tt.want)
+			}
+
+			if got != nil && strings.Join(got, "") != tt.src {
+				t.Errorf("joined units don't round-trip: %q", strings.Join(got, ""))
+			}
+		})
+	}
+}
+
+func TestHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		header string
+	}{
+		{
+			name:   "frontmatter",
+			code:   "// Copyright\n/*---\nflags: [onlyStrict]\n---*/\nfoo();\n",
+			header: "// Copyright\n/*---\nflags: [onlyStrict]\n---*/\n",
+		},
+		{
+			name:   "no frontmatter",
+			code:   "foo();\n",
+			header: "",
+		},
+		{
+			name:   "unterminated frontmatter",
+			code:   "/*---\nfoo();\n",
+			header: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, body := Header(tt.code)
+			if header != tt.header {
+				t.Errorf("header = %q, want %q", header, tt.header)
+			}
+			if header+body != tt.code {
+				t.Errorf("header and body don't round-trip: %q", header+body)
+			}
+		})
+	}
+}
+
+func TestBody(t *testing.T) {
+	tests := []struct {
+		name             string
+		unit             string
+		head, body, tail string
+		ok               bool
+	}{
+		{
+			name: "function",
+			unit: "\nfunction f(a = {}) {\n  foo();\n}",
+			head: "\nfunction f(a = {}) {",
+			body: "\n  foo();",
+			tail: "\n}",
+			ok:   true,
+		},
+		{
+			name: "nested",
+			unit: "if (x) {\n  if (y) {\n    foo();\n  }\n} else {\n  bar();\n}",
+			head: "if (x) {",
+			body: "\n  if (y) {\n    foo();\n  }",
+			tail: "\n} else {\n  bar();\n}",
+			ok:   true,
+		},
+		{
+			name: "no block",
+			unit: "foo([1, 2]);",
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head, body, tail, ok := Body(tt.unit)
+			if ok != tt.ok || head != tt.head || body != tt.body || tail != tt.tail {
+				t.Errorf("Body(%q) = %q, %q, %q, %v, want %q, %q, %q, %v", tt.unit, head, body, tail, ok, tt.head, tt.body, tt.tail, tt.ok)
+			}
+			if ok && head+body+tail != tt.unit {
+				t.Errorf("head, body and tail don't round-trip: %q", head+body+tail)
+			}
+		})
+	}
+}
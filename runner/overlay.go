@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"github.com/Sharktheone/mcp262/provider"
+	"github.com/Sharktheone/mcp262/runner/overlay"
+)
+
+// overlayRunner runs tests with the edits of an overlay, results of edited tests are marked as modified and not recorded.
+type overlayRunner struct {
+	*Runner
+
+	overlay provider.Overlay
+}
+
+// WithOverlay returns a runner that materializes the edited tests and harness files into a temporary
+// test262 tree for every run. Only results of tests unaffected by the edits are recorded.
+func (r *Runner) WithOverlay(o provider.Overlay) provider.TestRunner {
+	if o.Empty() {
+		return r
+	}
+
+	return &overlayRunner{Runner: r, overlay: o}
+}
+
+func (or *overlayRunner) tree() (*overlay.Tree, error) {
+	return overlay.New(or.testRoot, or.overlay.Tests, or.overlay.Harness)
+}
+
+func (or *overlayRunner) RerunTest(testPath string, rebuild bool) (provider.TestResult, error) {
+	tree, err := or.tree()
+	if err != nil {
+		return provider.TestResult{}, err
+	}
+
+	defer tree.Close()
+
+	return or.rerunTest(testPath, rebuild, tree)
+}
+
+func (or *overlayRunner) RerunTestsInDir(dir string, rebuild bool) (map[string]provider.TestResult, error) {
+	tree, err := or.tree()
+	if err != nil {
+		return nil, err
+	}
+
+	defer tree.Close()
+
+	return or.rerunTestsInDir(dir, rebuild, tree)
+}
+
+func (or *overlayRunner) RerunTestsInDirChanges(dir string, rebuild bool) ([]provider.TestDiff, error) {
+	tree, err := or.tree()
+	if err != nil {
+		return nil, err
+	}
+
+	defer tree.Close()
+
+	return or.rerunTestsInDirChanges(dir, rebuild, tree)
+}
+
+// RunSnippet runs the snippet with the edited harness files of the overlay.
+func (or *overlayRunner) RunSnippet(s provider.Snippet) (provider.SnippetResult, error) {
+	return or.runSnippet(s, or.overlay.Harness)
+}
+
+// MinimizeTest reduces the test as edited in the overlay, with the edited harness files.
+func (or *overlayRunner) MinimizeTest(testPath string, rebuild bool, maxRuns int) (provider.MinimizedTest, error) {
+	return or.minimizeTest(testPath, rebuild, maxRuns, or.overlay)
+}
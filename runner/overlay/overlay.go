@@ -0,0 +1,168 @@
+package overlay
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const HARNESS_DIR = "harness"
+
+// Tree is a temporary copy of the test262 checkout with edited tests and harness files written into it.
+// Only the directories containing edited files are created, every other entry links to the checkout.
+type Tree struct {
+	src      string
+	root     string
+	testDir  string
+	tests    map[string]bool
+	harness  bool
+	expanded map[string]bool
+}
+
+// New materializes the edits, tests are relative to testRoot and harness files to the harness directory next to it.
+func New(testRoot string, tests map[string]string, harness map[string]string) (*Tree, error) {
+	src, err := filepath.Abs(filepath.Dir(filepath.Clean(testRoot)))
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := os.MkdirTemp("", "mcp262-overlay-")
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tree{
+		src:      src,
+		root:     root,
+		testDir:  filepath.Base(filepath.Clean(testRoot)),
+		tests:    make(map[string]bool, len(tests)),
+		harness:  len(harness) > 0,
+		expanded: make(map[string]bool),
+	}
+
+	for p, code := range tests {
+		if err := t.write(t.testDir, p, code); err != nil {
+			_ = t.Close()
+			return nil, err
+		}
+
+		t.tests[p] = true
+	}
+
+	for p, code := range harness {
+		if err := t.write(HARNESS_DIR, p, code); err != nil {
+			_ = t.Close()
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// TestRoot is the test directory of the tree, to be used in place of the original test root.
+func (t *Tree) TestRoot() string {
+	return filepath.Join(t.root, t.testDir)
+}
+
+func (t *Tree) Path(testPath string) string {
+	return filepath.Join(t.TestRoot(), testPath)
+}
+
+// Modified reports whether the result of a test is produced from edited sources, which is every test if a harness file is edited.
+func (t *Tree) Modified(testPath string) bool {
+	return t.harness || t.tests[testPath]
+}
+
+// Added returns the edited tests in dir that don't exist in the checkout.
+func (t *Tree) Added(dir string) []string {
+	var added []string
+
+	for p := range t.tests {
+		if dir != "" && p != dir && !strings.HasPrefix(p, dir+"/") {
+			continue
+		}
+
+		if _, err := os.Lstat(filepath.Join(t.src, t.testDir, p)); os.IsNotExist(err) {
+			added = append(added, p)
+		}
+	}
+
+	return added
+}
+
+// Write adds or replaces a test in the tree after it was created.
+func (t *Tree) Write(testPath string, code string) error {
+	if err := t.write(t.testDir, testPath, code); err != nil {
+		return err
+	}
+
+	t.tests[testPath] = true
+
+	return nil
+}
+
+func (t *Tree) Close() error {
+	return os.RemoveAll(t.root)
+}
+
+func (t *Tree) write(dir string, p string, code string) error {
+	if !filepath.IsLocal(p) {
+		return errors.New("invalid path " + p)
+	}
+
+	rel := path.Join(dir, filepath.ToSlash(p))
+
+	if err := t.expand(path.Dir(rel)); err != nil {
+		return err
+	}
+
+	full := filepath.Join(t.root, rel)
+	if err := os.Remove(full); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return os.WriteFile(full, []byte(code), 0644)
+}
+
+// expand turns dir into a real directory with links to the entries of the original directory.
+func (t *Tree) expand(dir string) error {
+	if dir == "." {
+		dir = ""
+	}
+
+	if t.expanded[dir] {
+		return nil
+	}
+
+	if dir != "" {
+		if err := t.expand(path.Dir(dir)); err != nil {
+			return err
+		}
+	}
+
+	full := filepath.Join(t.root, dir)
+	if err := os.Remove(full); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.MkdirAll(full, 0755); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(t.src, dir))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, e := range entries {
+		if err := os.Symlink(filepath.Join(t.src, dir, e.Name()), filepath.Join(full, e.Name())); err != nil {
+			return err
+		}
+	}
+
+	t.expanded[dir] = true
+
+	return nil
+}
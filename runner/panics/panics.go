@@ -0,0 +1,105 @@
+package panics
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const NOT_IMPLEMENTED_MSG = "not yet implemented"
+
+// Location is a parsed rust panic: the message and the source location it was raised at.
+type Location struct {
+	Message string `json:"message"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+func (l *Location) Key() string {
+	return l.File + ":" + strconv.Itoa(l.Line) + ":" + strconv.Itoa(l.Column)
+}
+
+func (l *Location) IsNotImplemented() bool {
+	return strings.HasPrefix(l.Message, NOT_IMPLEMENTED_MSG)
+}
+
+// rust >= 1.73:
+//
+//	thread 'main' panicked at crates/yavashark/src/foo.rs:123:5:
+//	not yet implemented: something
+var panicRe = regexp.MustCompile(`thread '[^']*' panicked at ([^\s:]+):(\d+):(\d+):\s*\n([^\n]*)`)
+
+// rust < 1.73:
+//
+//	thread 'main' panicked at 'not yet implemented', crates/yavashark/src/foo.rs:123:5
+var legacyPanicRe = regexp.MustCompile(`thread '[^']*' panicked at '((?:[^'\\]|\\.)*)', ([^\s:]+):(\d+):(\d+)`)
+
+// Parse extracts the first panic from the output of a test run. It returns nil if the output contains no panic.
+func Parse(out string) *Location {
+	if m := panicRe.FindStringSubmatch(out); m != nil {
+		return newLocation(m[4], m[1], m[2], m[3])
+	}
+
+	if m := legacyPanicRe.FindStringSubmatch(out); m != nil {
+		return newLocation(m[1], m[2], m[3], m[4])
+	}
+
+	return nil
+}
+
+func newLocation(msg, file, line, col string) *Location {
+	l, _ := strconv.Atoi(line)
+	c, _ := strconv.Atoi(col)
+
+	return &Location{
+		Message: strings.TrimSpace(msg),
+		File:    file,
+		Line:    l,
+		Column:  c,
+	}
+}
+
+// Ranked is a source location together with all tests that panicked there.
+type Ranked struct {
+	Location
+	Count int      `json:"count"`
+	Tests []string `json:"tests"`
+}
+
+// Rank groups tests by the location they panicked at, most blocking location first.
+func Rank(locations map[string]*Location) []Ranked {
+	grouped := make(map[string]*Ranked)
+
+	for test, loc := range locations {
+		if loc == nil {
+			continue
+		}
+
+		key := loc.Key()
+		r, ok := grouped[key]
+		if !ok {
+			r = &Ranked{Location: *loc}
+			grouped[key] = r
+		}
+
+		r.Count++
+		r.Tests = append(r.Tests, test)
+	}
+
+	out := make([]Ranked, 0, len(grouped))
+	for _, r := range grouped {
+		sort.Strings(r.Tests)
+		out = append(out, *r)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Key() < out[j].Key()
+	})
+
+	return out
+}
@@ -0,0 +1,131 @@
+package rebuild
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const MAX_DIAGNOSTICS = 100
+
+type Diagnostic struct {
+	Level    string `json:"level"`
+	Code     string `json:"code,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+	Rendered string `json:"rendered,omitempty"`
+}
+
+type BuildResult struct {
+	Profile  string        `json:"profile"`
+	State    BuildState    `json:"state"`
+	Success  bool          `json:"success"`
+	Commit   string        `json:"commit"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	Errors   []Diagnostic  `json:"errors"`
+	Warnings []Diagnostic  `json:"warnings"`
+	// Stderr holds cargo's own output, useful when the build fails before rustc emits diagnostics.
+	Stderr string `json:"stderr,omitempty"`
+}
+
+type BuildError struct {
+	Result *BuildResult
+	Err    error
+}
+
+func (e *BuildError) Error() string {
+	var sb strings.Builder
+
+	_, _ = fmt.Fprintf(&sb, "%s build failed: %v", e.Result.Profile, e.Err)
+
+	for _, d := range e.Result.Errors {
+		sb.WriteString("\n")
+		if d.Rendered != "" {
+			sb.WriteString(d.Rendered)
+		} else {
+			_, _ = fmt.Fprintf(&sb, "%s: %s (%s:%d:%d)", d.Level, d.Message, d.File, d.Line, d.Column)
+		}
+	}
+
+	if len(e.Result.Errors) == 0 && e.Result.Stderr != "" {
+		sb.WriteString("\n")
+		sb.WriteString(e.Result.Stderr)
+	}
+
+	return sb.String()
+}
+
+func (e *BuildError) Unwrap() error {
+	return e.Err
+}
+
+type cargoMessage struct {
+	Reason  string `json:"reason"`
+	Message *struct {
+		Message  string `json:"message"`
+		Level    string `json:"level"`
+		Rendered string `json:"rendered"`
+		Code     *struct {
+			Code string `json:"code"`
+		} `json:"code"`
+		Spans []struct {
+			FileName    string `json:"file_name"`
+			LineStart   int    `json:"line_start"`
+			ColumnStart int    `json:"column_start"`
+			IsPrimary   bool   `json:"is_primary"`
+		} `json:"spans"`
+	} `json:"message"`
+}
+
+// parseCargoMessages reads the output of `cargo build --message-format=json` and collects the compiler diagnostics.
+func parseCargoMessages(r io.Reader, res *BuildResult) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		var msg cargoMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+
+		if msg.Reason != "compiler-message" || msg.Message == nil {
+			continue
+		}
+
+		d := Diagnostic{
+			Level:    msg.Message.Level,
+			Message:  msg.Message.Message,
+			Rendered: msg.Message.Rendered,
+		}
+
+		if msg.Message.Code != nil {
+			d.Code = msg.Message.Code.Code
+		}
+
+		for _, span := range msg.Message.Spans {
+			if span.IsPrimary {
+				d.File = span.FileName
+				d.Line = span.LineStart
+				d.Column = span.ColumnStart
+				break
+			}
+		}
+
+		switch {
+		case strings.HasPrefix(d.Level, "error"):
+			if len(res.Errors) < MAX_DIAGNOSTICS {
+				res.Errors = append(res.Errors, d)
+			}
+		case d.Level == "warning":
+			if len(res.Warnings) < MAX_DIAGNOSTICS {
+				res.Warnings = append(res.Warnings, d)
+			}
+		}
+	}
+}
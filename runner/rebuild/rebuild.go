@@ -1,13 +1,16 @@
 package rebuild
 
 import (
+	"bytes"
 	"context"
+	"log"
 	"os/exec"
 	"path/filepath"
 	"sync/atomic"
 )
 
 const RELEASE_BUILD_THRESHOLD uint32 = 5000
+const MAX_STDERR = 8 * 1024
 
 type EngineLocation struct {
 	ReleasePath string
@@ -17,10 +20,16 @@ type EngineLocation struct {
 }
 
 func (engine *EngineLocation) GetPath() string {
+	path, _ := engine.Get()
+	return path
+}
+
+// Get returns the path of the engine binary to use and the profile it was built with.
+func (engine *EngineLocation) Get() (string, string) {
 	if engine.UseDebug.Load() {
-		return engine.DebugPath
+		return engine.DebugPath, PROFILE_DEBUG
 	}
-	return engine.ReleasePath
+	return engine.ReleasePath, PROFILE_RELEASE
 }
 
 func RebuildEngine(repoRoot string, numTests uint32, rebuild bool) (*EngineLocation, context.CancelFunc, error) {
@@ -38,10 +47,15 @@ func RebuildEngine(repoRoot string, numTests uint32, rebuild bool) (*EngineLocat
 		DebugPath:   repoRoot + "/target/debug/yavashark_test262",
 	}
 
+	engine.UseDebug.Store(true)
+
 	if numTests > RELEASE_BUILD_THRESHOLD && rebuild {
 		go func() {
 			releaseErr := rebuildReleaseEngine(repoRoot, ctx)
 			if releaseErr != nil {
+				if ctx.Err() == nil {
+					log.Printf("Release build failed, continuing with the debug engine: %v", releaseErr)
+				}
 				cancel()
 				return
 			}
@@ -51,24 +65,60 @@ func RebuildEngine(repoRoot string, numTests uint32, rebuild bool) (*EngineLocat
 
 	}
 
-	engine.UseDebug.Store(true)
-
 	return engine, cancel, nil
 
 }
 
 func rebuildDebugEngine(repoRoot string) error {
-	cmd := exec.Command("cargo", "build")
-
-	cmd.Dir = filepath.Join(repoRoot, "crates/yavashark_test262")
-
-	return cmd.Run()
+	return cargoBuild(context.Background(), repoRoot, PROFILE_DEBUG)
 }
 
 func rebuildReleaseEngine(repoRoot string, ctx context.Context) error {
-	cmd := exec.CommandContext(ctx, "cargo", "build", "--release")
+	return cargoBuild(ctx, repoRoot, PROFILE_RELEASE, "--release")
+}
+
+func cargoBuild(ctx context.Context, repoRoot string, profile string, args ...string) error {
+	args = append([]string{"build", "--message-format=json"}, args...)
+
+	cmd := exec.CommandContext(ctx, "cargo", args...)
 
 	cmd.Dir = filepath.Join(repoRoot, "crates/yavashark_test262")
 
-	return cmd.Run()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	res := startBuild(repoRoot, profile)
+
+	err := cmd.Run()
+
+	state := BUILD_SUCCEEDED
+	if ctx.Err() != nil {
+		state = BUILD_CANCELLED
+	} else if err != nil {
+		state = BUILD_FAILED
+	}
+
+	var diag BuildResult
+	parseCargoMessages(&stdout, &diag)
+
+	if err != nil {
+		diag.Stderr = tail(stderr.String(), MAX_STDERR)
+	}
+
+	snapshot := finishBuild(res, state, diag)
+
+	if err != nil {
+		return &BuildError{Result: snapshot, Err: err}
+	}
+
+	return nil
+}
+
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[len(s)-n:]
 }
@@ -0,0 +1,128 @@
+package rebuild
+
+import (
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+type BuildState string
+
+const (
+	BUILD_RUNNING   BuildState = "RUNNING"
+	BUILD_SUCCEEDED BuildState = "SUCCEEDED"
+	BUILD_FAILED    BuildState = "FAILED"
+	BUILD_CANCELLED BuildState = "CANCELLED"
+)
+
+const (
+	PROFILE_DEBUG   = "debug"
+	PROFILE_RELEASE = "release"
+)
+
+var (
+	stateMu     sync.Mutex
+	builds      = make(map[string]*BuildResult)
+	lastProfile string
+	// lastFinished is the latest build that wasn't cancelled, the binary results come from
+	lastFinished *BuildResult
+)
+
+// LastBuild returns a snapshot of the latest cargo build that finished without being cancelled, so a release build
+// cancelled at the end of a run doesn't hide the debug build that produced the results. If none finished yet
+// the most recently started build is returned, or nil if nothing was built yet.
+func LastBuild() *BuildResult {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	if lastFinished != nil {
+		return lastFinished.snapshot()
+	}
+
+	if b, ok := builds[lastProfile]; ok {
+		return b.snapshot()
+	}
+
+	return nil
+}
+
+// Builds returns a snapshot of the latest build for every profile.
+func Builds() []*BuildResult {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	out := make([]*BuildResult, 0, len(builds))
+	for _, profile := range []string{PROFILE_DEBUG, PROFILE_RELEASE} {
+		if b, ok := builds[profile]; ok {
+			out = append(out, b.snapshot())
+		}
+	}
+
+	return out
+}
+
+func startBuild(repoRoot string, profile string) *BuildResult {
+	res := &BuildResult{
+		Profile: profile,
+		State:   BUILD_RUNNING,
+		Started: time.Now(),
+		Commit:  gitCommit(repoRoot),
+	}
+
+	stateMu.Lock()
+	builds[profile] = res
+	lastProfile = profile
+	stateMu.Unlock()
+
+	return res
+}
+
+// finishBuild records the outcome of a build, diag holds the diagnostics parsed before taking the lock.
+func finishBuild(res *BuildResult, state BuildState, diag BuildResult) *BuildResult {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	res.Errors = diag.Errors
+	res.Warnings = diag.Warnings
+	res.Stderr = diag.Stderr
+	res.State = state
+	res.Success = state == BUILD_SUCCEEDED
+	res.Duration = time.Since(res.Started)
+
+	if state != BUILD_CANCELLED {
+		lastFinished = res
+	}
+
+	return res.snapshot()
+}
+
+func (b *BuildResult) snapshot() *BuildResult {
+	s := *b
+	if s.State == BUILD_RUNNING {
+		s.Duration = time.Since(s.Started)
+	}
+
+	return &s
+}
+
+func gitCommit(repoRoot string) string {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = repoRoot
+
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	commit := strings.TrimSpace(string(out))
+
+	cmd = exec.Command("git", "status", "--porcelain", "--untracked-files=no")
+	cmd.Dir = repoRoot
+
+	if dirty, err := cmd.Output(); err == nil && strings.TrimSpace(string(dirty)) != "" {
+		commit += "-dirty"
+	}
+
+	return commit
+}
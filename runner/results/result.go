@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/Sharktheone/mcp262/runner/panics"
 	"github.com/Sharktheone/mcp262/runner/status"
 )
 
@@ -20,6 +21,12 @@ type Result struct {
 	Path     string        `json:"path"`
 	MemoryKB uint64        `json:"memory_kb"`
 	Duration time.Duration `json:"duration"`
+
+	Panic *panics.Location `json:"panic,omitempty"`
+	// Engine is the build profile (debug or release) of the binary that produced this result.
+	Engine string `json:"engine,omitempty"`
+	// Modified is set if the result was produced from edited tests or harness files.
+	Modified bool `json:"modified,omitempty"`
 }
 
 type CIResult struct {
@@ -32,7 +39,7 @@ func writeResults(results []Result) error {
 }
 
 func LoadResults() ([]Result, error) {
-	return loadResultsPath(RESULT_PATH)
+	return LoadResultsPath(RESULT_PATH)
 }
 
 func writeResultsPath(results []Result, path string) error {
@@ -46,7 +53,7 @@ func writeResultsPath(results []Result, path string) error {
 	return nil
 }
 
-func loadResultsPath(path string) ([]Result, error) {
+func LoadResultsPath(path string) ([]Result, error) {
 	contents, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
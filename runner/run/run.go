@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Sharktheone/mcp262/runner/overlay"
 	"github.com/Sharktheone/mcp262/runner/rebuild"
 	"github.com/Sharktheone/mcp262/runner/test"
 
@@ -22,7 +23,17 @@ var SKIP = []string{
 }
 
 func RunTestsInDir(testRoot string, testDir string, repoRoot string, workers int, rebuildEngine bool) (*results.TestResults, error) {
-	num := countTests(filepath.Join(testRoot, testDir))
+	return RunTestsInDirOverlay(testRoot, testDir, repoRoot, workers, rebuildEngine, nil)
+}
+
+// RunTestsInDirOverlay lists the tests in testRoot but runs them from the overlay tree if it isn't nil.
+func RunTestsInDirOverlay(testRoot string, testDir string, repoRoot string, workers int, rebuildEngine bool, tree *overlay.Tree) (*results.TestResults, error) {
+	var added []string
+	if tree != nil {
+		added = tree.Added(testDir)
+	}
+
+	num := countTests(filepath.Join(testRoot, testDir)) + uint32(len(added))
 
 	loc, cancel, err := rebuild.RebuildEngine(repoRoot, num, rebuildEngine)
 
@@ -30,14 +41,14 @@ func RunTestsInDir(testRoot string, testDir string, repoRoot string, workers int
 		return nil, err
 	}
 
-	res := testsInDir(testRoot, testDir, repoRoot, workers, loc, num)
+	res := testsInDir(testRoot, testDir, repoRoot, workers, loc, num, tree, added)
 
 	cancel()
 
 	return res, nil
 }
 
-func testsInDir(testRoot, testDir, repoRoot string, workers int, loc *rebuild.EngineLocation, num uint32) *results.TestResults {
+func testsInDir(testRoot, testDir, repoRoot string, workers int, loc *rebuild.EngineLocation, num uint32, tree *overlay.Tree, added []string) *results.TestResults {
 	jobs := make(chan worker.Job, workers*8)
 	testsDir := filepath.Join(testRoot, testDir)
 
@@ -53,10 +64,15 @@ func testsInDir(testRoot, testDir, repoRoot string, workers int, loc *rebuild.En
 
 	testResults := results.New(num)
 
+	collected := make(chan struct{})
 	go func() {
 		for res := range resultsChan {
+			if tree != nil {
+				res.Modified = tree.Modified(res.Path)
+			}
 			testResults.Add(res)
 		}
+		close(collected)
 	}()
 
 	now := time.Now()
@@ -94,6 +110,10 @@ func testsInDir(testRoot, testDir, repoRoot string, workers int, loc *rebuild.En
 			}
 		}
 
+		if tree != nil {
+			path = tree.Path(p)
+		}
+
 		jobs <- worker.Job{
 			FullPath:     path,
 			RelativePath: p,
@@ -102,17 +122,30 @@ func testsInDir(testRoot, testDir, repoRoot string, workers int, loc *rebuild.En
 		return nil
 	})
 
+	for _, p := range added {
+		jobs <- worker.Job{
+			FullPath:     tree.Path(p),
+			RelativePath: p,
+		}
+	}
+
 	close(jobs)
 
 	wg.Wait()
 	log.Printf("Finished running %d tests in %s", num, time.Since(now).String())
 
 	close(resultsChan)
+	<-collected
 
 	return testResults
 }
 
 func RunSingleTest(testRoot string, testPath string, repoRoot string, rebuildEngine bool) (results.Result, error) {
+	return RunSingleTestOverlay(testRoot, testPath, repoRoot, rebuildEngine, nil)
+}
+
+// RunSingleTestOverlay runs the test from the overlay tree if it isn't nil.
+func RunSingleTestOverlay(testRoot string, testPath string, repoRoot string, rebuildEngine bool, tree *overlay.Tree) (results.Result, error) {
 	loc, cancel, err := rebuild.RebuildEngine(repoRoot, 1, rebuildEngine)
 
 	if err != nil {
@@ -121,11 +154,38 @@ func RunSingleTest(testRoot string, testPath string, repoRoot string, rebuildEng
 
 	cancel()
 
-	engine := loc.GetPath()
+	engine, profile := loc.Get()
 
 	fullPath := filepath.Join(testRoot, testPath)
+	if tree != nil {
+		fullPath = tree.Path(testPath)
+	}
 
-	return test.RunTest(testPath, fullPath, engine, repoRoot), nil
+	res := test.RunTest(testPath, fullPath, engine, repoRoot)
+	res.Engine = profile
+	if tree != nil {
+		res.Modified = tree.Modified(testPath)
+	}
+
+	return res, nil
+}
+
+// RunSnippet runs a file staged in the overlay tree that isn't a test, results are not marked as modified.
+func RunSnippet(tree *overlay.Tree, snippetPath string, repoRoot string, rebuildEngine bool, timeout time.Duration) (results.Result, error) {
+	loc, cancel, err := rebuild.RebuildEngine(repoRoot, 1, rebuildEngine)
+
+	if err != nil {
+		return results.Result{}, err
+	}
+
+	cancel()
+
+	engine, profile := loc.Get()
+
+	res := test.RunTestTimeout(snippetPath, tree.Path(snippetPath), engine, repoRoot, timeout)
+	res.Engine = profile
+
+	return res, nil
 }
 
 func countTests(path string) uint32 {
@@ -2,19 +2,47 @@ package runner
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/Sharktheone/mcp262/fetch"
 	"github.com/Sharktheone/mcp262/provider"
+	"github.com/Sharktheone/mcp262/provider/yavashark"
 	"github.com/Sharktheone/mcp262/runner/ci"
+	"github.com/Sharktheone/mcp262/runner/export"
+	"github.com/Sharktheone/mcp262/runner/importer"
+	"github.com/Sharktheone/mcp262/runner/overlay"
+	"github.com/Sharktheone/mcp262/runner/panics"
+	"github.com/Sharktheone/mcp262/runner/rebuild"
 	"github.com/Sharktheone/mcp262/runner/results"
 	"github.com/Sharktheone/mcp262/runner/run"
+	"github.com/Sharktheone/mcp262/runner/status"
 )
 
+var ErrOutsideBaselineDir = errors.New("path is outside the baseline directory")
+
 type Runner struct {
-	testRoot string
-	repoRoot string
-	workers  int
+	testRoot  string
+	repoRoot  string
+	workers   int
+	outputDir string
+	// baselineDir is where LoadBaseline may load results from
+	baselineDir string
+
+	// ciSource is where the CI results reruns are diffed against come from, unless a baseline is loaded
+	ciSource fetch.Source
+	prev     *results.TestResults
+
+	mu   sync.RWMutex
+	last map[string]results.Result
 
-	prev *results.TestResults
+	listeners []provider.ResultListener
 }
 
 func New(testRoot, repoRoot string, workers int) *Runner {
@@ -22,38 +50,61 @@ func New(testRoot, repoRoot string, workers int) *Runner {
 		testRoot: testRoot,
 		repoRoot: repoRoot,
 		workers:  workers,
+		ciSource: yavashark.DefaultResultsSource,
+		last:     make(map[string]results.Result),
+	}
+}
+
+func NewFromConfig(config *Config) (*Runner, error) {
+	r := New(config.TestRootDir, config.RepoPath, config.Workers)
+	r.outputDir = config.OutputDir
+	r.baselineDir = config.BaselineDir
+	r.ciSource = config.Sources.Results
+
+	if config.Baseline.Path != "" {
+		if _, err := r.loadBaseline(config.Baseline.Format, config.Baseline.Path, config.Baseline.Engine); err != nil {
+			return nil, err
+		}
+	} else if config.Provider == PROVIDER_YAVASHARK_DIR {
+		if _, err := r.loadBaseline(string(importer.CI), filepath.Join(config.DataDir, "results.json"), ""); err != nil {
+			return nil, err
+		}
 	}
+
+	return r, nil
 }
 
 func (r *Runner) RerunTest(testPath string, rebuild bool) (provider.TestResult, error) {
-	res, err := run.RunSingleTest(r.testRoot, testPath, r.repoRoot, rebuild)
+	return r.rerunTest(testPath, rebuild, nil)
+}
+
+func (r *Runner) rerunTest(testPath string, rebuild bool, tree *overlay.Tree) (provider.TestResult, error) {
+	res, err := run.RunSingleTestOverlay(r.testRoot, testPath, r.repoRoot, rebuild, tree)
 	if err != nil {
-		return provider.TestResult{}, err
+		return provider.TestResult{}, wrapBuildError(err)
 	}
 
-	return provider.TestResult{
-		TestPath: res.Path,
-		Status:   res.Status.String(),
-		Output:   res.Msg,
-		Duration: res.Duration.String(),
-	}, nil
+	r.record(res)
+
+	return toTestResult(res), nil
 
 }
 
 func (r *Runner) RerunTestsInDir(dir string, rebuild bool) (map[string]provider.TestResult, error) {
-	tres, err := run.RunTestsInDir(r.testRoot, dir, r.repoRoot, r.workers, rebuild)
+	return r.rerunTestsInDir(dir, rebuild, nil)
+}
+
+func (r *Runner) rerunTestsInDir(dir string, rebuild bool, tree *overlay.Tree) (map[string]provider.TestResult, error) {
+	tres, err := run.RunTestsInDirOverlay(r.testRoot, dir, r.repoRoot, r.workers, rebuild, tree)
 	if err != nil {
-		return nil, err
+		return nil, wrapBuildError(err)
 	}
 
+	r.record(tres.TestResults...)
+
 	testResults := make(map[string]provider.TestResult)
 	for _, res := range tres.TestResults {
-		testResults[res.Path] = provider.TestResult{
-			TestPath: res.Path,
-			Status:   string(res.Status),
-			Output:   res.Msg,
-			Duration: res.Duration.String(),
-		}
+		testResults[res.Path] = toTestResult(res)
 	}
 
 	return testResults, nil
@@ -64,19 +115,42 @@ func (r *Runner) RerunFailedTestsInDir(dir string, rebuild bool) (map[string]pro
 }
 
 func (r *Runner) RerunTestsInDirChanges(dir string, rebuild bool) ([]provider.TestDiff, error) {
-	tres, err := run.RunTestsInDir(r.testRoot, dir, r.repoRoot, r.workers, rebuild)
+	return r.rerunTestsInDirChanges(dir, rebuild, nil)
+}
+
+func (r *Runner) rerunTestsInDirChanges(dir string, rebuild bool, tree *overlay.Tree) ([]provider.TestDiff, error) {
+	tres, err := run.RunTestsInDirOverlay(r.testRoot, dir, r.repoRoot, r.workers, rebuild, tree)
 	if err != nil {
-		return nil, err
+		return nil, wrapBuildError(err)
 	}
 
+	r.record(tres.TestResults...)
+
 	prev, err := r.getPrevResults()
 
 	if err != nil {
 		return nil, err
 	}
 
-	diff := tres.ComputeDiff(prev)
+	return toTestDiffs(tres.ComputeDiff(prev)), nil
+}
+
+// DiffLastRun diffs the latest local results in dir against the baseline without running anything.
+func (r *Runner) DiffLastRun(dir string) ([]provider.TestDiff, error) {
+	last := r.lastInDir(dir)
+	if len(last) == 0 {
+		return nil, errors.New("no local results in directory, rerun the tests first")
+	}
+
+	prev, err := r.getPrevResults()
+	if err != nil {
+		return nil, err
+	}
+
+	return toTestDiffs(results.FromResults(last).ComputeDiff(prev)), nil
+}
 
+func toTestDiffs(diff results.Diff) []provider.TestDiff {
 	diffs := make([]provider.TestDiff, 0, len(diff))
 
 	for d, items := range diff {
@@ -94,25 +168,358 @@ func (r *Runner) RerunTestsInDirChanges(dir string, rebuild bool) ([]provider.Te
 		diffs = append(diffs, td)
 	}
 
-	return diffs, nil
+	return diffs
 }
 
 func (r *Runner) RerunFailedTestsInDirChanges(dir string, rebuild bool) ([]provider.TestDiff, error) {
 	return nil, errors.New("not implemented")
 }
 
+func (r *Runner) RankPanicLocations(dir string, statusFilter string) ([]provider.RankedPanicLocation, int, error) {
+	var filter []status.Status
+	if statusFilter != "" {
+		s, err := status.ParseStatus(strings.ToUpper(statusFilter))
+		if err != nil {
+			return nil, 0, err
+		}
+		filter = []status.Status{s}
+	} else {
+		filter = []status.Status{status.NOT_IMPLEMENTED, status.CRASH}
+	}
+
+	locations := make(map[string]*panics.Location)
+	unlocated := 0
+
+	for _, res := range r.lastInDir(dir) {
+		if !slices.Contains(filter, res.Status) {
+			continue
+		}
+
+		if res.Panic == nil {
+			unlocated++
+			continue
+		}
+
+		locations[res.Path] = res.Panic
+	}
+
+	if len(locations) == 0 {
+		names := make([]string, len(filter))
+		for i, s := range filter {
+			names[i] = s.String()
+		}
+		return nil, unlocated, fmt.Errorf("no local %s results with a panic location in directory (%d without one), rerun the tests first", strings.Join(names, " or "), unlocated)
+	}
+
+	ranked := panics.Rank(locations)
+
+	out := make([]provider.RankedPanicLocation, len(ranked))
+	for i, rl := range ranked {
+		out[i] = provider.RankedPanicLocation{
+			Message: rl.Message,
+			File:    rl.File,
+			Line:    rl.Line,
+			Column:  rl.Column,
+			Count:   rl.Count,
+			Tests:   rl.Tests,
+		}
+	}
+
+	return out, unlocated, nil
+}
+
+func (r *Runner) LastBuild() (*provider.BuildStatus, error) {
+	res := rebuild.LastBuild()
+	if res == nil {
+		return nil, errors.New("engine has not been built yet")
+	}
+
+	return toBuildStatus(res), nil
+}
+
+func (r *Runner) Builds() ([]provider.BuildStatus, error) {
+	builds := rebuild.Builds()
+
+	out := make([]provider.BuildStatus, len(builds))
+	for i, b := range builds {
+		out[i] = *toBuildStatus(b)
+	}
+
+	return out, nil
+}
+
+func (r *Runner) ExportResults(dir string, format string, write bool) (provider.ExportedResults, error) {
+	f, err := export.ParseFormat(format)
+	if err != nil {
+		return provider.ExportedResults{}, err
+	}
+
+	res := r.lastInDir(dir)
+	if len(res) == 0 {
+		return provider.ExportedResults{}, errors.New("no local results in directory, rerun the tests first")
+	}
+
+	out := provider.ExportedResults{
+		Format: string(f),
+		Count:  len(res),
+	}
+
+	if !write {
+		var sb strings.Builder
+		if err := export.Write(&sb, f, res); err != nil {
+			return provider.ExportedResults{}, err
+		}
+
+		out.Content = sb.String()
+
+		return out, nil
+	}
+
+	if r.outputDir == "" {
+		return provider.ExportedResults{}, errors.New("no output_dir configured")
+	}
+
+	if err := os.MkdirAll(r.outputDir, 0755); err != nil {
+		return provider.ExportedResults{}, err
+	}
+
+	out.Path = filepath.Join(r.outputDir, "results."+f.Extension())
+
+	file, err := os.Create(out.Path)
+	if err != nil {
+		return provider.ExportedResults{}, err
+	}
+
+	if err := export.Write(file, f, res); err != nil {
+		_ = file.Close()
+		return provider.ExportedResults{}, err
+	}
+
+	// a short write or full disk may only show up when closing
+	if err := file.Close(); err != nil {
+		return provider.ExportedResults{}, err
+	}
+
+	return out, nil
+}
+
+// lastInDir returns the latest local results for all tests in dir (recursively).
+func (r *Runner) lastInDir(dir string) []results.Result {
+	dir = path.Clean(dir)
+	if dir == "." || dir == "/" {
+		dir = ""
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]results.Result, 0)
+	for p, res := range r.last {
+		if dir != "" && p != dir && !strings.HasPrefix(p, dir+"/") {
+			continue
+		}
+
+		out = append(out, res)
+	}
+
+	return out
+}
+
+// AddResultListener registers a listener that is called with the results of every run.
+func (r *Runner) AddResultListener(l provider.ResultListener) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.listeners = append(r.listeners, l)
+}
+
+// record keeps the results of a run as the latest local results and passes them to the listeners. Results of edited
+// tests or harness files only belong to the session that made the edits and are left out.
+func (r *Runner) record(all ...results.Result) {
+	res := make([]results.Result, 0, len(all))
+	for _, tr := range all {
+		if !tr.Modified {
+			res = append(res, tr)
+		}
+	}
+
+	r.mu.Lock()
+
+	for _, tr := range res {
+		r.last[tr.Path] = tr
+	}
+
+	listeners := r.listeners
+
+	r.mu.Unlock()
+
+	if len(listeners) == 0 {
+		return
+	}
+
+	testResults := make([]provider.TestResult, 0, len(res))
+	for _, tr := range res {
+		// results without an engine were never run (e.g. skipped directories)
+		if tr.Engine == "" {
+			continue
+		}
+
+		testResults = append(testResults, toTestResult(tr))
+	}
+
+	for _, l := range listeners {
+		l(testResults)
+	}
+}
+
+// LoadBaseline replaces the results reruns are compared against with imported results from a path inside the
+// baseline directory.
+func (r *Runner) LoadBaseline(format string, path string, engine string) (int, error) {
+	full, err := r.resolveBaseline(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.loadBaseline(format, full, engine)
+}
+
+// resolveBaseline maps a path relative to the baseline directory to a path on disk and rejects anything
+// outside of it, including through symlinks.
+func (r *Runner) resolveBaseline(p string) (string, error) {
+	if r.baselineDir == "" {
+		return "", errors.New("loading baselines is disabled, no baseline_dir is configured")
+	}
+
+	if !filepath.IsLocal(p) {
+		return "", ErrOutsideBaselineDir
+	}
+
+	root, err := filepath.EvalSymlinks(r.baselineDir)
+	if err != nil {
+		return "", errors.New("the baseline directory is not accessible")
+	}
+
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+
+	full, err := filepath.EvalSymlinks(filepath.Join(root, p))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", p, os.ErrNotExist)
+	}
+
+	if rel, err := filepath.Rel(root, full); err != nil || !filepath.IsLocal(rel) {
+		return "", ErrOutsideBaselineDir
+	}
+
+	return full, nil
+}
+
+func (r *Runner) loadBaseline(format string, path string, engine string) (int, error) {
+	f, err := importer.ParseFormat(format)
+	if err != nil {
+		return 0, err
+	}
+
+	prev, err := importer.Load(f, path, engine)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	r.prev = prev
+	r.mu.Unlock()
+
+	return len(prev.TestResults), nil
+}
+
 func (r *Runner) getPrevResults() (*results.TestResults, error) {
-	if r.prev != nil {
-		return r.prev, nil
+	r.mu.RLock()
+	prev := r.prev
+	r.mu.RUnlock()
+
+	if prev != nil {
+		return prev, nil
 	}
 
-	prev, err := ci.LoadPrevCiGithub()
+	prev, err := ci.LoadPrevCiGithub(r.ciSource)
 
 	if err != nil {
 		return nil, err
 	}
 
+	r.mu.Lock()
 	r.prev = prev
+	r.mu.Unlock()
 
 	return prev, nil
 }
+
+func toTestResult(res results.Result) provider.TestResult {
+	return provider.TestResult{
+		TestPath: res.Path,
+		Status:   res.Status.String(),
+		Output:   res.Msg,
+		Duration: res.Duration.String(),
+		Engine:   res.Engine,
+		Modified: res.Modified,
+		Panic:    toPanicLocation(res.Panic),
+	}
+}
+
+func toPanicLocation(loc *panics.Location) *provider.PanicLocation {
+	if loc == nil {
+		return nil
+	}
+
+	return &provider.PanicLocation{
+		Message: loc.Message,
+		File:    loc.File,
+		Line:    loc.Line,
+		Column:  loc.Column,
+	}
+}
+
+func wrapBuildError(err error) error {
+	var buildErr *rebuild.BuildError
+	if errors.As(err, &buildErr) {
+		return &provider.BuildError{
+			Build: toBuildStatus(buildErr.Result),
+			Err:   buildErr,
+		}
+	}
+
+	return err
+}
+
+func toBuildStatus(res *rebuild.BuildResult) *provider.BuildStatus {
+	return &provider.BuildStatus{
+		Profile:  res.Profile,
+		State:    string(res.State),
+		Success:  res.Success,
+		Commit:   res.Commit,
+		Started:  res.Started.Format(time.RFC3339),
+		Duration: res.Duration.String(),
+		Errors:   toBuildDiagnostics(res.Errors),
+		Warnings: toBuildDiagnostics(res.Warnings),
+		Stderr:   res.Stderr,
+	}
+}
+
+func toBuildDiagnostics(diags []rebuild.Diagnostic) []provider.BuildDiagnostic {
+	out := make([]provider.BuildDiagnostic, len(diags))
+	for i, d := range diags {
+		out[i] = provider.BuildDiagnostic{
+			Level:    d.Level,
+			Code:     d.Code,
+			File:     d.File,
+			Line:     d.Line,
+			Column:   d.Column,
+			Message:  d.Message,
+			Rendered: d.Rendered,
+		}
+	}
+
+	return out
+}
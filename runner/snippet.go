@@ -0,0 +1,109 @@
+package runner
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Sharktheone/mcp262/provider"
+	"github.com/Sharktheone/mcp262/runner/overlay"
+	"github.com/Sharktheone/mcp262/runner/run"
+	"github.com/Sharktheone/mcp262/runner/test"
+)
+
+// SNIPPET_PATH is where snippets are staged in the temporary test tree.
+const SNIPPET_PATH = "mcp262-snippet.js"
+
+const MAX_SNIPPET_TIMEOUT = 5 * time.Minute
+
+// snippetFlags maps the accepted flags to test262 frontmatter flags.
+var snippetFlags = map[string]string{
+	"module":     "module",
+	"strict":     "onlyStrict",
+	"onlyStrict": "onlyStrict",
+	"noStrict":   "noStrict",
+	"async":      "async",
+	"raw":        "raw",
+}
+
+func (r *Runner) RunSnippet(s provider.Snippet) (provider.SnippetResult, error) {
+	return r.runSnippet(s, nil)
+}
+
+// runSnippet stages the snippet with a frontmatter for its includes and flags in a temporary test tree,
+// with the edited harness files if there are any.
+func (r *Runner) runSnippet(s provider.Snippet, harness map[string]string) (provider.SnippetResult, error) {
+	code, err := snippetCode(s)
+	if err != nil {
+		return provider.SnippetResult{}, err
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = test.TIMEOUT
+	}
+	timeout = min(timeout, MAX_SNIPPET_TIMEOUT)
+
+	tree, err := overlay.New(r.testRoot, map[string]string{SNIPPET_PATH: code}, harness)
+	if err != nil {
+		return provider.SnippetResult{}, err
+	}
+
+	defer tree.Close()
+
+	res, err := run.RunSnippet(tree, SNIPPET_PATH, r.repoRoot, s.Rebuild, timeout)
+	if err != nil {
+		return provider.SnippetResult{}, wrapBuildError(err)
+	}
+
+	return provider.SnippetResult{
+		Status:   res.Status.String(),
+		Output:   res.Msg,
+		Duration: res.Duration.String(),
+		MemoryKB: res.MemoryKB,
+		Engine:   res.Engine,
+		Panic:    toPanicLocation(res.Panic),
+	}, nil
+}
+
+func snippetCode(s provider.Snippet) (string, error) {
+	if strings.Contains(s.Source, "/*---") {
+		if len(s.Includes) > 0 || len(s.Flags) > 0 {
+			return "", errors.New("the source has a frontmatter, put the includes and flags into it")
+		}
+
+		return s.Source, nil
+	}
+
+	var flags []string
+	for _, f := range s.Flags {
+		flag, ok := snippetFlags[f]
+		if !ok {
+			return "", errors.New("unknown flag " + f + ", expected module, strict, noStrict, async or raw")
+		}
+
+		flags = append(flags, flag)
+	}
+
+	for _, inc := range s.Includes {
+		if strings.ContainsAny(inc, "[],\n") {
+			return "", errors.New("invalid include " + inc)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("/*---\ndescription: snippet\n")
+
+	if len(s.Includes) > 0 {
+		b.WriteString("includes: [" + strings.Join(s.Includes, ", ") + "]\n")
+	}
+
+	if len(flags) > 0 {
+		b.WriteString("flags: [" + strings.Join(flags, ", ") + "]\n")
+	}
+
+	b.WriteString("---*/\n")
+	b.WriteString(s.Source)
+
+	return b.String(), nil
+}
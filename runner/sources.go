@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"errors"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Sharktheone/mcp262/fetch"
+	"github.com/Sharktheone/mcp262/provider/github"
+	"github.com/Sharktheone/mcp262/provider/yavashark"
+)
+
+// SourcesConfig configures where the GitHub providers fetch from.
+type SourcesConfig struct {
+	Test262  fetch.Source `toml:"test262"`
+	Spec     fetch.Source `toml:"spec"`
+	IntlSpec fetch.Source `toml:"intl_spec"`
+	// Results is a yavashark-data repository, used for the CI results and test outputs.
+	Results fetch.Source `toml:"results"`
+
+	// PinTest262 fetches test262 code at the commit the results were produced against, so the code matches what was tested.
+	// Test262CommitPath is the file of the results source holding that commit, without it the commit of the local
+	// test262 checkout is used.
+	PinTest262        bool   `toml:"pin_test262"`
+	Test262CommitPath string `toml:"test262_commit_path"`
+}
+
+func NewSourcesConfig() SourcesConfig {
+	return SourcesConfig{
+		Test262:  github.DefaultTest262Source,
+		Spec:     github.DefaultSpecSource,
+		IntlSpec: github.DefaultIntlSpecSource,
+		Results:  yavashark.DefaultResultsSource,
+	}
+}
+
+// Test262Source returns the test262 source, pinned to the commit the results were produced against if PinTest262 is set.
+// The commit is an explicit commit ref, the one in Test262CommitPath of the results or else the commit of the local
+// checkout at testRoot. If none can be determined the source is returned unpinned with a warning.
+func (c *Config) Test262Source() fetch.Source {
+	source := c.Sources.Test262
+	if !c.Sources.PinTest262 || fetch.IsCommit(source.Ref) {
+		return source
+	}
+
+	if c.Sources.Test262CommitPath != "" {
+		commit, err := c.resultsTest262Commit()
+		if err == nil {
+			source.Ref = commit
+			return source
+		}
+
+		log.Printf("Failed to read the test262 commit of the results, using the local checkout: %v", err)
+	} else {
+		log.Printf("No test262_commit_path for the results, pinning test262 to the local checkout, which may differ from the results")
+	}
+
+	commit, err := Test262Commit(c.TestRootDir)
+	if err != nil {
+		log.Printf("Not pinning test262, fetching %s: %v", source.Ref, err)
+		return source
+	}
+
+	source.Ref = commit
+
+	return source
+}
+
+// resultsTest262Commit reads the test262 commit recorded next to the results, from DataDir for the yavashark-dir provider.
+func (c *Config) resultsTest262Commit() (string, error) {
+	var contents []byte
+	var err error
+	if c.Provider == PROVIDER_YAVASHARK_DIR {
+		contents, err = os.ReadFile(filepath.Join(c.DataDir, filepath.FromSlash(c.Sources.Test262CommitPath)))
+	} else {
+		contents, err = c.Sources.Results.Get(c.Sources.Test262CommitPath)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	commit := strings.TrimSpace(string(contents))
+	if !fetch.IsCommit(commit) {
+		return "", errors.New(c.Sources.Test262CommitPath + " doesn't contain a commit hash")
+	}
+
+	return commit, nil
+}
+
+// Test262Commit returns the commit of the test262 checkout containing testRoot.
+func Test262Commit(testRoot string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = testRoot
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Join(errors.New("failed to determine the test262 commit of "+testRoot), err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
@@ -41,6 +41,11 @@ func (s Status) String() string {
 	}
 }
 
+// Failed reports whether s is a failure, which is every status but PASS and SKIP.
+func (s Status) Failed() bool {
+	return s != PASS && s != SKIP
+}
+
 func (s Status) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s.String())
 }
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Sharktheone/mcp262/runner/panics"
 	"github.com/Sharktheone/mcp262/runner/results"
 	"github.com/Sharktheone/mcp262/runner/status"
 )
@@ -20,9 +21,13 @@ const (
 )
 
 func RunTest(path, fullPath, engine, root string) results.Result {
+	return RunTestTimeout(path, fullPath, engine, root, TIMEOUT)
+}
+
+func RunTestTimeout(path, fullPath, engine, root string, timeout time.Duration) results.Result {
 	startTime := time.Now()
 
-	ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, engine, fullPath)
@@ -96,13 +101,16 @@ func RunTest(path, fullPath, engine, root string) results.Result {
 			}
 		}
 
-		if strings.Contains(out, "not yet implemented") && strings.Contains(out, "thread '") && strings.Contains(out, "' panicked at") {
+		loc := panics.Parse(out)
+
+		if loc != nil && loc.IsNotImplemented() || strings.Contains(out, panics.NOT_IMPLEMENTED_MSG) && strings.Contains(out, "thread '") && strings.Contains(out, "' panicked at") {
 			return results.Result{
 				Status:   status.NOT_IMPLEMENTED,
 				Msg:      out,
 				Path:     path,
 				MemoryKB: peakMemoryKB,
 				Duration: duration,
+				Panic:    loc,
 			}
 		}
 		return results.Result{
@@ -111,6 +119,7 @@ func RunTest(path, fullPath, engine, root string) results.Result {
 			Path:     path,
 			MemoryKB: peakMemoryKB,
 			Duration: duration,
+			Panic:    loc,
 		}
 	}
 
@@ -170,6 +179,7 @@ func RunTest(path, fullPath, engine, root string) results.Result {
 		Path:     path,
 		MemoryKB: peakMemoryKB,
 		Duration: duration,
+		Panic:    panics.Parse(out),
 	}
 }
 
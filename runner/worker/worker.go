@@ -17,9 +17,10 @@ func Worker(id int, root string, jobs <-chan Job, results chan<- results.Result,
 	defer wg.Done()
 
 	for job := range jobs {
-		engine := loc.GetPath()
+		engine, profile := loc.Get()
 
 		res := test.RunTest(job.RelativePath, job.FullPath, engine, root)
+		res.Engine = profile
 
 		results <- res
 	}
@@ -0,0 +1,76 @@
+package testtree
+
+type Transition struct {
+	From string
+	To   string
+}
+
+// Changes describes how a tree changed when it was replaced by a newer snapshot.
+type Changes struct {
+	Transitions map[Transition]int
+	Added       int
+	Removed     int
+	// KeptLocal counts tests whose local result was carried over into the new snapshot,
+	// DroppedLocal tests whose local result was replaced by a changed CI result.
+	KeptLocal    int
+	DroppedLocal int
+}
+
+// Replace atomically swaps the contents of the tree with next, which must not be used afterward.
+// Results of local runs are carried over, so a refreshed CI snapshot does not hide what was just run,
+// unless the CI status of the test changed since, then the newer CI result replaces the local one.
+func (tt *TestTree) Replace(next *TestTree) Changes {
+	changes, changed := tt.replace(next)
+
+	tt.notify(changed)
+
+	return changes
+}
+
+func (tt *TestTree) replace(next *TestTree) (Changes, []string) {
+	changes := Changes{
+		Transitions: make(map[Transition]int),
+	}
+	changed := make([]string, 0)
+
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	for p, f := range tt.Files {
+		nf, exists := next.Files[p]
+
+		if f.Source == SOURCE_LOCAL {
+			if !exists || nf.Status == f.CIStatus {
+				next.addFile(p, f.Status, f.Source, f.Produced)
+				next.Files[p].CIStatus = f.CIStatus
+				changes.KeptLocal++
+				continue
+			}
+
+			changes.DroppedLocal++
+		}
+
+		if !exists {
+			changes.Removed++
+			changed = append(changed, p)
+			continue
+		}
+
+		if f.Status != nf.Status {
+			changes.Transitions[Transition{From: f.Status, To: nf.Status}]++
+			changed = append(changed, p)
+		}
+	}
+
+	for p := range next.Files {
+		if _, exists := tt.Files[p]; !exists {
+			changes.Added++
+			changed = append(changed, p)
+		}
+	}
+
+	tt.Files = next.Files
+	tt.Directories = next.Directories
+
+	return changes, changed
+}
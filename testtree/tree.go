@@ -5,18 +5,63 @@ import (
 	"path"
 	"sort"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sharktheone/mcp262/runner/status"
+)
+
+const (
+	SOURCE_CI    = "ci"
+	SOURCE_LOCAL = "local"
 )
 
+// TestTree is safe for concurrent use through its methods; Files and Directories must not be
+// accessed directly once the tree is shared.
 type TestTree struct {
 	Files       map[string]*TestTreeFile
 	Directories map[string]*TestTreeDir
+
+	mu        sync.RWMutex
+	listeners []ChangeListener
+}
+
+// ChangeListener is called with the paths of tests that were added, removed or changed status.
+type ChangeListener func(paths []string)
+
+// AddListener registers l to be called after every change, outside of the tree's lock.
+func (tt *TestTree) AddListener(l ChangeListener) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	tt.listeners = append(tt.listeners, l)
+}
+
+func (tt *TestTree) notify(paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+
+	tt.mu.RLock()
+	listeners := tt.listeners
+	tt.mu.RUnlock()
+
+	for _, l := range listeners {
+		l(paths)
+	}
 }
 
 func (tt *TestTree) NumTests() int {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
 	return len(tt.Files)
 }
 
 func (tt *TestTree) NumTestsInDir(dir string) (int, error) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
 	d := normalizeDir(dir)
 	if d, exists := tt.Directories[d]; exists {
 		return len(d.Files), nil
@@ -26,6 +71,9 @@ func (tt *TestTree) NumTestsInDir(dir string) (int, error) {
 }
 
 func (tt *TestTree) NumTestsInDirRec(dir string) (int, error) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
 	d := normalizeDir(dir)
 	if d, exists := tt.Directories[d]; exists {
 		count := 0
@@ -44,6 +92,9 @@ func (tt *TestTree) NumTestsInDirRec(dir string) (int, error) {
 }
 
 func (tt *TestTree) GetTestsInDir(dir string) ([]string, error) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
 	d := normalizeDir(dir)
 	if d, exists := tt.Directories[d]; exists {
 		out := make([]string, 0)
@@ -61,6 +112,9 @@ func (tt *TestTree) GetTestsInDir(dir string) ([]string, error) {
 }
 
 func (tt *TestTree) GetTestsInDirRec(dir string) ([]string, error) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
 	d := normalizeDir(dir)
 	if d, exists := tt.Directories[d]; exists {
 		out := make([]string, 0)
@@ -80,13 +134,50 @@ func (tt *TestTree) GetTestsInDirRec(dir string) ([]string, error) {
 }
 
 func (tt *TestTree) GetTestStatus(testPath string) (string, error) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
 	if f, exists := tt.Files[testPath]; exists {
 		return f.Status, nil
 	}
 	return "", errors.New("test not found")
 }
 
+// GetTestSource returns where the status of a test comes from and when it was produced.
+func (tt *TestTree) GetTestSource(testPath string) (string, time.Time, error) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
+	if f, exists := tt.Files[testPath]; exists {
+		return f.Source, f.Produced, nil
+	}
+	return "", time.Time{}, errors.New("test not found")
+}
+
+// DirPaths returns the paths of all directories, the root excluded.
+func (tt *TestTree) DirPaths() []string {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
+	out := make([]string, 0, len(tt.Directories))
+	for p := range tt.Directories {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+
+	return out
+}
+
+// Tree gives providers embedding a TestTree access to the underlying tree.
+func (tt *TestTree) Tree() *TestTree {
+	return tt
+}
+
 func (tt *TestTree) GetTestStatusesInDir(dir string) (map[string]string, error) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
 	d := normalizeDir(dir)
 	if d, exists := tt.Directories[d]; exists {
 		res := make(map[string]string, len(d.Files))
@@ -99,6 +190,9 @@ func (tt *TestTree) GetTestStatusesInDir(dir string) (map[string]string, error)
 }
 
 func (tt *TestTree) GetTestStatusesInDirRec(dir string) (map[string]string, error) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
 	d := normalizeDir(dir)
 	if d, exists := tt.Directories[d]; exists {
 		res := make(map[string]string)
@@ -118,6 +212,9 @@ func (tt *TestTree) GetTestStatusesInDirRec(dir string) (map[string]string, erro
 }
 
 func (tt *TestTree) GetTestsWithStatusInDir(dir string, status string) ([]string, error) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
 	d := normalizeDir(dir)
 	if d, exists := tt.Directories[d]; exists {
 		out := make([]string, 0)
@@ -132,6 +229,9 @@ func (tt *TestTree) GetTestsWithStatusInDir(dir string, status string) ([]string
 }
 
 func (tt *TestTree) GetTestsWithStatusInDirRec(dir string, status string) ([]string, error) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
 	d := normalizeDir(dir)
 	if d, exists := tt.Directories[d]; exists {
 		out := make([]string, 0)
@@ -153,6 +253,9 @@ func (tt *TestTree) GetTestsWithStatusInDirRec(dir string, status string) ([]str
 }
 
 func (tt *TestTree) SearchDir(query string) ([]string, error) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
 	q := strings.ToLower(query)
 
 	if root, ok := tt.Directories[""]; ok {
@@ -187,6 +290,9 @@ func (tt *TestTree) SearchDir(query string) ([]string, error) {
 }
 
 func (tt *TestTree) SearchDirIn(dir string, query string) ([]string, error) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
 	dn := normalizeDir(dir)
 	d, exists := tt.Directories[dn]
 	if !exists {
@@ -215,6 +321,9 @@ func (tt *TestTree) SearchDirIn(dir string, query string) ([]string, error) {
 }
 
 func (tt *TestTree) SearchTest(query string) ([]string, error) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
 	q := strings.ToLower(query)
 
 	if root, ok := tt.Directories[""]; ok {
@@ -254,6 +363,9 @@ func (tt *TestTree) SearchTest(query string) ([]string, error) {
 }
 
 func (tt *TestTree) SearchTestInDir(dir string, query string) ([]string, error) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
 	dn := normalizeDir(dir)
 	d, exists := tt.Directories[dn]
 	if !exists {
@@ -287,6 +399,22 @@ func (tt *TestTree) SearchTestInDir(dir string, query string) ([]string, error)
 type TestTreeFile struct {
 	Path   string
 	Status string
+	// Source is where the status comes from (SOURCE_CI or SOURCE_LOCAL) and Produced when it was produced.
+	Source   string
+	Produced time.Time
+	// CIStatus is the status of the CI results a local result replaced, empty if the test wasn't in them.
+	CIStatus string
+}
+
+// set replaces the status, the CI status is remembered when a CI result is replaced.
+func (f *TestTreeFile) set(status string, source string, produced time.Time) {
+	if f.Source == SOURCE_CI {
+		f.CIStatus = f.Status
+	}
+
+	f.Status = status
+	f.Source = source
+	f.Produced = produced
 }
 
 type TestTreeDir struct {
@@ -323,23 +451,157 @@ func normalizeDir(p string) string {
 	return cleaned
 }
 
+// FailedTests returns the tests of statuses that failed, statuses are status.Status names.
+func FailedTests(statuses map[string]string) []string {
+	out := make([]string, 0)
+	for p, s := range statuses {
+		if st, err := status.ParseStatus(s); err == nil && st.Failed() {
+			out = append(out, p)
+		}
+	}
+
+	return out
+}
+
 func (tt *TestTree) AddFile(p string, status string) {
+	tt.AddFileFrom(p, status, SOURCE_CI, time.Time{})
+}
+
+func (tt *TestTree) AddFileFrom(p string, status string, source string, produced time.Time) {
+	tt.mu.Lock()
+	prev, exists := tt.Files[p]
+	changed := !exists || prev.Status != status
+	tt.addFile(p, status, source, produced)
+	tt.mu.Unlock()
+
+	if changed {
+		tt.notify([]string{p})
+	}
+}
+
+func (tt *TestTree) addFile(p string, status string, source string, produced time.Time) {
 	f := &TestTreeFile{
-		Path:   p,
-		Status: status,
+		Path:     p,
+		Status:   status,
+		Source:   source,
+		Produced: produced,
 	}
 
 	tt.Files[p] = f
 
 	// Determine directory for the file and normalize it
 	dirPath := normalizeDir(path.Dir(p))
-	tt.AddDir(dirPath)
+	tt.addDir(dirPath)
 
 	dir := tt.Directories[dirPath]
 	dir.Files[p] = f
 }
 
+// SetStatus updates the status of a test already in the tree.
+func (tt *TestTree) SetStatus(p string, status string, source string, produced time.Time) error {
+	tt.mu.Lock()
+
+	f, exists := tt.Files[p]
+	if !exists {
+		tt.mu.Unlock()
+		return errors.New("test not found")
+	}
+
+	changed := f.Status != status
+
+	f.set(status, source, produced)
+
+	tt.mu.Unlock()
+
+	if changed {
+		tt.notify([]string{p})
+	}
+
+	return nil
+}
+
+// SetStatuses updates the status of many tests under one lock, tests not in the tree yet are added.
+// Listeners are notified once with all changed paths.
+func (tt *TestTree) SetStatuses(files []TestTreeFile) {
+	var changed []string
+
+	tt.mu.Lock()
+	for _, file := range files {
+		f, exists := tt.Files[file.Path]
+		if !exists {
+			tt.addFile(file.Path, file.Status, file.Source, file.Produced)
+			changed = append(changed, file.Path)
+			continue
+		}
+
+		if f.Status != file.Status {
+			changed = append(changed, file.Path)
+		}
+
+		f.set(file.Status, file.Source, file.Produced)
+	}
+	tt.mu.Unlock()
+
+	tt.notify(changed)
+}
+
+// RemoveFile removes a test and every directory left empty by it.
+func (tt *TestTree) RemoveFile(p string) error {
+	if err := tt.removeFile(p); err != nil {
+		return err
+	}
+
+	tt.notify([]string{p})
+
+	return nil
+}
+
+func (tt *TestTree) removeFile(p string) error {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	if _, exists := tt.Files[p]; !exists {
+		return errors.New("test not found")
+	}
+
+	delete(tt.Files, p)
+
+	dirPath := normalizeDir(path.Dir(p))
+	if dir, exists := tt.Directories[dirPath]; exists {
+		delete(dir.Files, p)
+	}
+
+	tt.pruneDir(dirPath)
+
+	return nil
+}
+
+func (tt *TestTree) pruneDir(p string) {
+	for p != "" {
+		dir, exists := tt.Directories[p]
+		if !exists || len(dir.Files) > 0 || len(dir.Directories) > 0 {
+			return
+		}
+
+		delete(tt.Directories, p)
+
+		parent := normalizeDir(path.Dir(p))
+		if parentDir, exists := tt.Directories[parent]; exists {
+			delete(parentDir.Directories, p)
+		}
+
+		p = parent
+	}
+}
+
 func (tt *TestTree) AddDir(p string) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	tt.addDir(p)
+}
+
+func (tt *TestTree) addDir(p string) {
 	np := normalizeDir(p)
 	if _, exists := tt.Directories[np]; !exists {
 		dir := &TestTreeDir{
@@ -358,7 +620,7 @@ func (tt *TestTree) AddDir(p string) {
 				// shouldn't happen, but guard against infinite recursion
 				parent = ""
 			}
-			tt.AddDir(parent)
+			tt.addDir(parent)
 			parentDir := tt.Directories[parent]
 			parentDir.Directories[np] = dir
 		}
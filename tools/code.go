@@ -3,8 +3,10 @@ package tools
 import (
 	"context"
 	"errors"
+	"sort"
 	"strings"
 
+	"github.com/Sharktheone/mcp262/patch"
 	"github.com/Sharktheone/mcp262/provider"
 	"github.com/Sharktheone/mcp262/utils"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -36,8 +38,84 @@ type SetHarnessCodeParams struct {
 	Code     string `json:"code" jsonschema:"New code for the harness file"`
 }
 
-func GetTestCode(ctx context.Context, req *mcp.CallToolRequest, args GetTestCodeParams) (*mcp.CallToolResult, any, error) {
-	pv, err := getCodeProvider()
+type LineReplacement struct {
+	Start int    `json:"start" jsonschema:"First line to replace (1-based)"`
+	End   int    `json:"end" jsonschema:"Last line to replace (inclusive), start-1 to insert before start"`
+	Old   string `json:"old,omitempty" jsonschema:"Expected current text of the lines, the replacement conflicts if it differs"`
+	Text  string `json:"text" jsonschema:"New text of the lines, empty to delete them"`
+}
+
+type PatchTestCodeParams struct {
+	TestPath     string            `json:"test_path" jsonschema:"Path to the single test file (e.g. /test262/test/language/...)"`
+	Diff         string            `json:"diff,omitempty" jsonschema:"Unified diff of the file; file headers are optional and hunks are located by their context near the line in the hunk header"`
+	Replacements []LineReplacement `json:"replacements,omitempty" jsonschema:"Line-range replacements, all ranges refer to the current code; alternative to diff"`
+}
+
+type PatchHarnessCodeParams struct {
+	FilePath     string            `json:"file_path" jsonschema:"Path to the harness file (relative to harness root)"`
+	Diff         string            `json:"diff,omitempty" jsonschema:"Unified diff of the file; file headers are optional and hunks are located by their context near the line in the hunk header"`
+	Replacements []LineReplacement `json:"replacements,omitempty" jsonschema:"Line-range replacements, all ranges refer to the current code; alternative to diff"`
+}
+
+type TestCodeOutput struct {
+	TestPath string `json:"test_path" jsonschema:"Test path as requested"`
+	Code     string `json:"code" jsonschema:"Source code of the test"`
+}
+
+type HarnessOutput struct {
+	TestPath string            `json:"test_path,omitempty" jsonschema:"Test path as requested"`
+	Harness  map[string]string `json:"harness" jsonschema:"Source code by harness file path"`
+}
+
+type HarnessCodeOutput struct {
+	HarnessPath string `json:"harness_path" jsonschema:"Harness path as requested"`
+	Code        string `json:"code" jsonschema:"Source code of the harness file"`
+}
+
+type HarnessFilesOutput struct {
+	TestPath string   `json:"test_path,omitempty" jsonschema:"Test path as requested"`
+	Files    []string `json:"files" jsonschema:"Harness file paths"`
+}
+
+type SetTestCodeOutput struct {
+	TestPath  string `json:"test_path" jsonschema:"Test path as requested"`
+	Updated   bool   `json:"updated" jsonschema:"Whether the code was replaced"`
+	Workspace string `json:"workspace,omitempty" jsonschema:"Workspace the edit was made in"`
+}
+
+type SetHarnessCodeOutput struct {
+	FilePath  string `json:"file_path" jsonschema:"Harness path as requested"`
+	Updated   bool   `json:"updated" jsonschema:"Whether the code was replaced"`
+	Workspace string `json:"workspace,omitempty" jsonschema:"Workspace the edit was made in"`
+}
+
+type PatchCodeOutput struct {
+	Path      string           `json:"path" jsonschema:"Path as requested"`
+	Applied   bool             `json:"applied" jsonschema:"Whether the change was applied; nothing is applied if there is a conflict"`
+	Conflicts []patch.Conflict `json:"conflicts,omitempty" jsonschema:"Hunks or replacements (index, 1-based) that don't apply, with the line they were expected at and the expected and actual text"`
+	Diff      string           `json:"diff,omitempty" jsonschema:"Unified diff of the applied change"`
+	Workspace string           `json:"workspace,omitempty" jsonschema:"Workspace the edit was made in"`
+}
+
+type EditDiff struct {
+	Path  string `json:"path" jsonschema:"Test path (relative to test/) or harness path (relative to harness/)"`
+	Kind  string `json:"kind" jsonschema:"test or harness"`
+	Diff  string `json:"diff" jsonschema:"Unified diff against the original"`
+	Error string `json:"error,omitempty" jsonschema:"Why the original could not be loaded, the diff is against an empty file then"`
+}
+
+type EditsDiffOutput struct {
+	Workspace string     `json:"workspace" jsonschema:"Workspace of this session"`
+	Files     []EditDiff `json:"files" jsonschema:"Edited files that differ from the original, tests first"`
+}
+
+type ResetEditsOutput struct {
+	Reset     bool   `json:"reset" jsonschema:"Whether the edits were reset"`
+	Workspace string `json:"workspace,omitempty" jsonschema:"Workspace the edits were reset in"`
+}
+
+func GetTestCode(ctx context.Context, req *mcp.CallToolRequest, args GetTestCodeParams) (*mcp.CallToolResult, *TestCodeOutput, error) {
+	pv, err := getCodeProvider(req.Session)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -49,11 +127,20 @@ func GetTestCode(ctx context.Context, req *mcp.CallToolRequest, args GetTestCode
 	if err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"test_path": args.TestPath, "code": code}), nil, nil
+	return nil, &TestCodeOutput{TestPath: args.TestPath, Code: code}, nil
+}
+
+// harnessPath resolves the path of a harness file, the .js extension is optional.
+func harnessPath(p string) string {
+	p = utils.ResolvePath(p)
+	if !strings.HasSuffix(p, ".js") {
+		p += ".js"
+	}
+	return p
 }
 
-func GetHarnessForTest(ctx context.Context, req *mcp.CallToolRequest, args GetHarnessForTestParams) (*mcp.CallToolResult, any, error) {
-	pv, err := getCodeProvider()
+func GetHarnessForTest(ctx context.Context, req *mcp.CallToolRequest, args GetHarnessForTestParams) (*mcp.CallToolResult, *HarnessOutput, error) {
+	pv, err := getCodeProvider(req.Session)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -62,11 +149,11 @@ func GetHarnessForTest(ctx context.Context, req *mcp.CallToolRequest, args GetHa
 	if err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"test_path": args.TestPath, "harness": h}), nil, nil
+	return nil, &HarnessOutput{TestPath: args.TestPath, Harness: h}, nil
 }
 
-func GetHarness(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
-	pv, err := getCodeProvider()
+func GetHarness(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, *HarnessOutput, error) {
+	pv, err := getCodeProvider(req.Session)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -74,27 +161,24 @@ func GetHarness(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp
 	if err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"harness": h}), nil, nil
+	return nil, &HarnessOutput{Harness: h}, nil
 }
 
-func GetHarnessCode(ctx context.Context, req *mcp.CallToolRequest, args GetHarnessCodeParams) (*mcp.CallToolResult, any, error) {
-	pv, err := getCodeProvider()
+func GetHarnessCode(ctx context.Context, req *mcp.CallToolRequest, args GetHarnessCodeParams) (*mcp.CallToolResult, *HarnessCodeOutput, error) {
+	pv, err := getCodeProvider(req.Session)
 	if err != nil {
 		return nil, nil, err
 	}
-	p := utils.ResolvePath(args.HarnessPath)
-	if !strings.HasSuffix(p, ".js") {
-		p += ".js"
-	}
+	p := harnessPath(args.HarnessPath)
 	code, err := pv.GetHarnessCode(p)
 	if err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"harness_path": args.HarnessPath, "code": code}), nil, nil
+	return nil, &HarnessCodeOutput{HarnessPath: args.HarnessPath, Code: code}, nil
 }
 
-func GetHaressFiles(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
-	pv, err := getCodeProvider()
+func GetHaressFiles(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, *HarnessFilesOutput, error) {
+	pv, err := getCodeProvider(req.Session)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -102,11 +186,11 @@ func GetHaressFiles(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (
 	if err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"files": files}), nil, nil
+	return nil, &HarnessFilesOutput{Files: files}, nil
 }
 
-func GetHarnessFilesForTest(ctx context.Context, req *mcp.CallToolRequest, args GetHarnessFilesForTestParams) (*mcp.CallToolResult, any, error) {
-	pv, err := getCodeProvider()
+func GetHarnessFilesForTest(ctx context.Context, req *mcp.CallToolRequest, args GetHarnessFilesForTestParams) (*mcp.CallToolResult, *HarnessFilesOutput, error) {
+	pv, err := getCodeProvider(req.Session)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -115,11 +199,11 @@ func GetHarnessFilesForTest(ctx context.Context, req *mcp.CallToolRequest, args
 	if err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"test_path": args.TestPath, "files": files}), nil, nil
+	return nil, &HarnessFilesOutput{TestPath: args.TestPath, Files: files}, nil
 }
 
-func SetTestCode(ctx context.Context, req *mcp.CallToolRequest, args SetTestCodeParams) (*mcp.CallToolResult, any, error) {
-	pv, err := getCodeProvider()
+func SetTestCode(ctx context.Context, req *mcp.CallToolRequest, args SetTestCodeParams) (*mcp.CallToolResult, *SetTestCodeOutput, error) {
+	pv, err := getCodeProvider(req.Session)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -130,83 +214,259 @@ func SetTestCode(ctx context.Context, req *mcp.CallToolRequest, args SetTestCode
 	if err := pv.SetTestCode(p, args.Code); err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"test_path": args.TestPath, "updated": true}), nil, nil
+	return nil, &SetTestCodeOutput{TestPath: args.TestPath, Updated: true, Workspace: workspaceName(pv)}, nil
 }
 
-func SetHarnessCode(ctx context.Context, req *mcp.CallToolRequest, args SetHarnessCodeParams) (*mcp.CallToolResult, any, error) {
-	pv, err := getCodeProvider()
+func SetHarnessCode(ctx context.Context, req *mcp.CallToolRequest, args SetHarnessCodeParams) (*mcp.CallToolResult, *SetHarnessCodeOutput, error) {
+	pv, err := getCodeProvider(req.Session)
 	if err != nil {
 		return nil, nil, err
 	}
-	p := utils.ResolvePath(args.FilePath)
+	p := harnessPath(args.FilePath)
 	if err := pv.SetHarnessCode(p, args.Code); err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"file_path": args.FilePath, "updated": true}), nil, nil
+	return nil, &SetHarnessCodeOutput{FilePath: args.FilePath, Updated: true, Workspace: workspaceName(pv)}, nil
+}
+
+func PatchTestCode(ctx context.Context, req *mcp.CallToolRequest, args PatchTestCodeParams) (*mcp.CallToolResult, *PatchCodeOutput, error) {
+	pv, err := getCodeProvider(req.Session)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := utils.ResolvePath(args.TestPath)
+	if !strings.HasSuffix(p, ".js") {
+		p += ".js"
+	}
+	out := &PatchCodeOutput{Path: args.TestPath, Workspace: workspaceName(pv)}
+	update := applyPatch(out, "test/"+p, args.Diff, args.Replacements)
+	if w, ok := pv.(provider.EditWorkspace); ok {
+		err = w.UpdateTestCode(p, update)
+	} else {
+		err = updateCode(p, pv.GetTestCode, pv.SetTestCode, update)
+	}
+	return patchResult(out, err)
 }
 
-func ResetEdits(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
-	pv, err := getCodeProvider()
+func PatchHarnessCode(ctx context.Context, req *mcp.CallToolRequest, args PatchHarnessCodeParams) (*mcp.CallToolResult, *PatchCodeOutput, error) {
+	pv, err := getCodeProvider(req.Session)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := harnessPath(args.FilePath)
+	out := &PatchCodeOutput{Path: args.FilePath, Workspace: workspaceName(pv)}
+	update := applyPatch(out, "harness/"+p, args.Diff, args.Replacements)
+	if w, ok := pv.(provider.EditWorkspace); ok {
+		err = w.UpdateHarnessCode(p, update)
+	} else {
+		err = updateCode(p, pv.GetHarnessCode, pv.SetHarnessCode, update)
+	}
+	return patchResult(out, err)
+}
+
+func DiffEdits(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, *EditsDiffOutput, error) {
+	wp, err := getWorkspaceProvider()
+	if err != nil {
+		return nil, nil, err
+	}
+	w := wp.SessionWorkspace(req.Session)
+	o := w.Overlay()
+	base := w.Base()
+
+	out := &EditsDiffOutput{Workspace: w.Name(), Files: make([]EditDiff, 0, len(o.Tests)+len(o.Harness))}
+	var text strings.Builder
+
+	add := func(kind string, p string, code string, original func(string) (string, error)) {
+		d := EditDiff{Path: p, Kind: kind}
+		oldName := "a/" + kind + "/" + p
+		orig, err := original(p)
+		if err != nil {
+			d.Error, oldName = err.Error(), "/dev/null"
+		}
+		d.Diff = patch.Diff(oldName, "b/"+kind+"/"+p, orig, code)
+		if d.Diff == "" {
+			return
+		}
+		out.Files = append(out.Files, d)
+		text.WriteString(d.Diff)
+	}
+
+	for _, p := range sortedKeys(o.Tests) {
+		add("test", p, o.Tests[p], base.GetTestCode)
+	}
+	for _, p := range sortedKeys(o.Harness) {
+		add("harness", p, o.Harness[p], base.GetHarnessCode)
+	}
+
+	if text.Len() == 0 {
+		text.WriteString("no edits in workspace " + w.Name())
+	}
+
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text.String()}}}, out, nil
+}
+
+// errConflicts aborts an update whose patch doesn't apply to the current code.
+var errConflicts = errors.New("the patch conflicts with the current code")
+
+// applyPatch returns the update applying a patch, conflicts and the diff of the change are stored in out.
+func applyPatch(out *PatchCodeOutput, name string, diff string, replacements []LineReplacement) func(string) (string, error) {
+	return func(code string) (string, error) {
+		patched, conflicts, err := patchCode(code, diff, replacements)
+		if err != nil {
+			return "", err
+		}
+		if len(conflicts) > 0 {
+			out.Conflicts = conflicts
+			return "", errConflicts
+		}
+		out.Diff = patch.Diff("a/"+name, "b/"+name, code, patched)
+		return patched, nil
+	}
+}
+
+// updateCode updates code of providers without workspaces, which can't do it atomically.
+func updateCode(p string, get func(string) (string, error), set func(string, string) error, update func(string) (string, error)) error {
+	code, err := get(p)
+	if err != nil {
+		return err
+	}
+	patched, err := update(code)
+	if err != nil {
+		return err
+	}
+	return set(p, patched)
+}
+
+func patchResult(out *PatchCodeOutput, err error) (*mcp.CallToolResult, *PatchCodeOutput, error) {
+	if errors.Is(err, errConflicts) {
+		return &mcp.CallToolResult{IsError: true}, out, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	out.Applied = true
+	return nil, out, nil
+}
+
+// patchCode applies either a unified diff or line-range replacements to code.
+func patchCode(code string, diff string, replacements []LineReplacement) (string, []patch.Conflict, error) {
+	if (diff == "") == (len(replacements) == 0) {
+		return "", nil, errors.New("exactly one of diff and replacements is required")
+	}
+
+	if diff != "" {
+		hunks, err := patch.Parse(diff)
+		if err != nil {
+			return "", nil, err
+		}
+		patched, conflicts := patch.Apply(code, hunks)
+		return patched, conflicts, nil
+	}
+
+	reps := make([]patch.Replacement, len(replacements))
+	for i, r := range replacements {
+		reps[i] = patch.Replacement{Start: r.Start, End: r.End, Old: r.Old, Text: r.Text}
+	}
+	patched, conflicts := patch.Replace(code, reps)
+	return patched, conflicts, nil
+}
+
+func ResetEdits(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, *ResetEditsOutput, error) {
+	pv, err := getCodeProvider(req.Session)
 	if err != nil {
 		return nil, nil, err
 	}
 	if err := pv.ResetEdits(); err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"reset": true}), nil, nil
+	return nil, &ResetEditsOutput{Reset: true, Workspace: workspaceName(pv)}, nil
 }
 
 func AddCodeTools(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetTestCode",
 		Description: "Get the source code for a single test",
 	}, GetTestCode)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetHarnessForTest",
 		Description: "Get harness files (map path->code) required by a test",
 	}, GetHarnessForTest)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetHarness",
 		Description: "Get all harness files (map path->code)",
 	}, GetHarness)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetHarnessCode",
 		Description: "Get the source code for a single harness file",
 	}, GetHarnessCode)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetHaressFiles",
 		Description: "List harness file paths ",
 	}, GetHaressFiles)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetHarnessFilesForTest",
 		Description: "List harness files used by a single test",
 	}, GetHarnessFilesForTest)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "SetTestCode",
-		Description: "Replace the source code for a single test",
+		Description: "Replace the source code for a single test in the edit workspace of this session",
 	}, SetTestCode)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "SetHarnessCode",
-		Description: "Replace the source code for a harness file",
+		Description: "Replace the source code for a harness file in the edit workspace of this session",
 	}, SetHarnessCode)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
+		Name:        "PatchTestCode",
+		Description: "Apply a unified diff or line-range replacements to a test in the edit workspace of this session; conflicts are reported and nothing is applied then",
+	}, PatchTestCode)
+
+	addTool(server, &mcp.Tool{
+		Name:        "PatchHarnessCode",
+		Description: "Apply a unified diff or line-range replacements to a harness file in the edit workspace of this session; conflicts are reported and nothing is applied then",
+	}, PatchHarnessCode)
+
+	addTool(server, &mcp.Tool{
+		Name:        "DiffEdits",
+		Description: "Show the unified diff of all edits of the edit workspace of this session against the original tests and harness files",
+	}, DiffEdits)
+
+	addTool(server, &mcp.Tool{
 		Name:        "ResetEdits",
-		Description: "Reset any in-memory edits to tests/harness",
+		Description: "Reset the in-memory edits to tests/harness of the edit workspace of this session",
 	}, ResetEdits)
 }
 
-// helper to validate provider is set
-func getCodeProvider() (provider.TestCodeProvider, error) {
+// helper to validate provider is set, edits are scoped to the workspace of the session if the provider supports it
+func getCodeProvider(session *mcp.ServerSession) (provider.TestCodeProvider, error) {
 	if provider.CodeProvider == nil {
 		return nil, errors.New("code provider not set")
 	}
+	if wp, ok := provider.CodeProvider.(provider.WorkspaceProvider); ok {
+		return wp.SessionWorkspace(session), nil
+	}
 	return provider.CodeProvider, nil
 }
+
+func workspaceName(pv provider.TestCodeProvider) string {
+	if w, ok := pv.(provider.EditWorkspace); ok {
+		return w.Name()
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
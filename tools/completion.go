@@ -0,0 +1,249 @@
+package tools
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Sharktheone/mcp262/provider"
+	"github.com/Sharktheone/mcp262/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MAX_COMPLETIONS is the maximum number of values a completion may return.
+const MAX_COMPLETIONS = 100
+
+type completionKind int
+
+const (
+	COMPLETE_NONE completionKind = iota
+	COMPLETE_TEST
+	COMPLETE_DIR
+	COMPLETE_HARNESS
+	COMPLETE_SECTION
+)
+
+// Complete is the mcp.ServerOptions CompletionHandler. Values are completed by prefix,
+// path arguments one directory level at a time; without a prefix match they are matched fuzzily.
+func Complete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	var ref mcp.CompleteReference
+	if req.Params.Ref != nil {
+		ref = *req.Params.Ref
+	}
+
+	value := req.Params.Argument.Value
+
+	var values []string
+	var err error
+
+	switch completionKindFor(ref, req.Params.Argument.Name) {
+	case COMPLETE_TEST:
+		values, err = completePath(value, true)
+	case COMPLETE_DIR:
+		values, err = completePath(value, false)
+	case COMPLETE_HARNESS:
+		values, err = completeHarness(req.Session, value)
+	case COMPLETE_SECTION:
+		values, err = completeSection(value)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(values)
+	if total > MAX_COMPLETIONS {
+		values = values[:MAX_COMPLETIONS]
+	}
+
+	if values == nil {
+		values = []string{}
+	}
+
+	return &mcp.CompleteResult{
+		Completion: mcp.CompletionResultDetails{
+			Values:  values,
+			Total:   total,
+			HasMore: total > len(values),
+		},
+	}, nil
+}
+
+func completionKindFor(ref mcp.CompleteReference, argument string) completionKind {
+	if ref.Type == "ref/resource" {
+		switch ref.URI {
+		case TEST_URI + "{+path}", RESULTS_URI + "{+path}":
+			return COMPLETE_TEST
+		case RESULTS_DIR_URI + "{+path}":
+			return COMPLETE_DIR
+		case HARNESS_URI + "{+file}":
+			return COMPLETE_HARNESS
+		case SECTION_URI + "{id}":
+			return COMPLETE_SECTION
+		}
+
+		return COMPLETE_NONE
+	}
+
+	switch argument {
+	case "test_path", "test":
+		return COMPLETE_TEST
+	case "path", "dir", "directory":
+		return COMPLETE_DIR
+	case "harness_path", "harness", "file_path":
+		return COMPLETE_HARNESS
+	case "section", "id", "spec_path", "intrinsic":
+		return COMPLETE_SECTION
+	}
+
+	return COMPLETE_NONE
+}
+
+// completePath completes the next path segment of value from the test tree, tests are only
+// included if withTests is set.
+func completePath(value string, withTests bool) ([]string, error) {
+	prov, err := getProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := utils.ResolvePath(value)
+
+	parent := ""
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		parent = prefix[:i]
+	}
+
+	out := make([]string, 0)
+
+	if entries, err := prov.GetTestsInDir(parent); err == nil {
+		for _, e := range entries {
+			if !strings.HasPrefix(e, prefix) {
+				continue
+			}
+
+			if !withTests && path.Ext(e) == ".js" {
+				continue
+			}
+
+			out = append(out, e)
+		}
+	}
+
+	if len(out) > 0 {
+		sort.Strings(out)
+		return out, nil
+	}
+
+	tp, ok := prov.(provider.TreeProvider)
+	if !ok {
+		return out, nil
+	}
+
+	var candidates []string
+	if withTests {
+		candidates, err = tp.Tree().GetTestsInDirRec("")
+		if err != nil {
+			return out, nil
+		}
+	}
+
+	candidates = append(candidates, tp.Tree().DirPaths()...)
+
+	return fuzzyMatch(candidates, prefix), nil
+}
+
+func completeHarness(session *mcp.ServerSession, value string) ([]string, error) {
+	pv, err := getCodeProvider(session)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := pv.GetHaressFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return prefixOrFuzzy(files, value), nil
+}
+
+func completeSection(value string) ([]string, error) {
+	sp, err := getSpecProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	sl, ok := sp.(provider.SectionLister)
+	if !ok {
+		return nil, nil
+	}
+
+	sections, err := sl.GetSections()
+	if err != nil {
+		return nil, err
+	}
+
+	value = strings.Trim(strings.TrimPrefix(value, "sec-"), "%")
+
+	return prefixOrFuzzy(sections, strings.ToLower(value)), nil
+}
+
+func prefixOrFuzzy(candidates []string, value string) []string {
+	out := make([]string, 0)
+	for _, c := range candidates {
+		if strings.HasPrefix(c, value) {
+			out = append(out, c)
+		}
+	}
+
+	if len(out) > 0 {
+		sort.Strings(out)
+		return out
+	}
+
+	return fuzzyMatch(candidates, value)
+}
+
+// fuzzyMatch returns the candidates containing query, or failing that containing its characters in order,
+// case-insensitive and with shorter candidates first.
+func fuzzyMatch(candidates []string, query string) []string {
+	q := strings.ToLower(query)
+
+	contains := make([]string, 0)
+	subsequence := make([]string, 0)
+
+	for _, c := range candidates {
+		lc := strings.ToLower(c)
+		if strings.Contains(lc, q) {
+			contains = append(contains, c)
+		} else if isSubsequence(lc, q) {
+			subsequence = append(subsequence, c)
+		}
+	}
+
+	out := contains
+	if len(out) == 0 {
+		out = subsequence
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if len(out[i]) != len(out[j]) {
+			return len(out[i]) < len(out[j])
+		}
+		return out[i] < out[j]
+	})
+
+	return out
+}
+
+func isSubsequence(s string, sub string) bool {
+	i := 0
+	for j := 0; j < len(s) && i < len(sub); j++ {
+		if s[j] == sub[i] {
+			i++
+		}
+	}
+
+	return i == len(sub)
+}
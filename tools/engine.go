@@ -0,0 +1,200 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Sharktheone/mcp262/provider"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const DefaultSnippetContext = 10
+
+type ReadEngineFileParams struct {
+	Path      string `json:"path" jsonschema:"Path of the file relative to the engine repository (e.g. crates/yavashark/src/lib.rs)"`
+	StartLine int    `json:"start_line" jsonschema:"First line to return (1-based); defaults to the start of the file"`
+	EndLine   int    `json:"end_line" jsonschema:"Last line to return (inclusive); defaults to the end of the file"`
+}
+
+type GetEngineSnippetParams struct {
+	Location string `json:"location" jsonschema:"Panic location as printed by rust or returned in a panic field (e.g. crates/yavashark/src/lib.rs:120:5)"`
+	Context  int    `json:"context" jsonschema:"Number of lines to include before and after the location; defaults to 10"`
+}
+
+type ListEngineDirParams struct {
+	Path     string `json:"path" jsonschema:"Directory relative to the engine repository; empty for the repository root"`
+	Page     int    `json:"page" jsonschema:"Page number starting from 1; defaults to 1"`
+	PageSize int    `json:"page_size" jsonschema:"Items per page; defaults to DefaultPageSize if omitted"`
+	Max      int    `json:"max" jsonschema:"Optional global maximum number of items to include across all pages; 0 means no limit"`
+}
+
+type GrepEngineParams struct {
+	Pattern  string `json:"pattern" jsonschema:"Regular expression (RE2 syntax) to search for"`
+	Dir      string `json:"dir" jsonschema:"Directory relative to the engine repository to restrict the search to; empty for the whole repository"`
+	Page     int    `json:"page" jsonschema:"Page number starting from 1; defaults to 1"`
+	PageSize int    `json:"page_size" jsonschema:"Items per page; defaults to DefaultPageSize if omitted"`
+	Max      int    `json:"max" jsonschema:"Optional global maximum number of matches to collect; defaults to 200"`
+}
+
+type EngineSnippetOutput struct {
+	Location string                 `json:"location" jsonschema:"Location as requested"`
+	Line     int                    `json:"line" jsonschema:"Line of the location"`
+	Snippet  provider.SourceSnippet `json:"snippet" jsonschema:"Source around the location"`
+}
+
+type EngineDirOutput struct {
+	Path      string                 `json:"path" jsonschema:"Directory as requested"`
+	Page      int                    `json:"page" jsonschema:"Page number starting from 1"`
+	PageSize  int                    `json:"page_size" jsonschema:"Items per page"`
+	Returned  int                    `json:"returned" jsonschema:"Number of items on this page"`
+	Remaining int                    `json:"remaining" jsonschema:"Number of items after this page (limited by max)"`
+	Total     int                    `json:"total" jsonschema:"Number of items across all pages"`
+	Entries   []provider.SourceEntry `json:"entries" jsonschema:"Files and directories"`
+}
+
+type GrepEngineOutput struct {
+	Pattern   string                 `json:"pattern" jsonschema:"Pattern as requested"`
+	Dir       string                 `json:"dir" jsonschema:"Directory the search is restricted to"`
+	Page      int                    `json:"page" jsonschema:"Page number starting from 1"`
+	PageSize  int                    `json:"page_size" jsonschema:"Items per page"`
+	Returned  int                    `json:"returned" jsonschema:"Number of items on this page"`
+	Remaining int                    `json:"remaining" jsonschema:"Number of items after this page"`
+	Total     int                    `json:"total" jsonschema:"Number of matches collected (limited by max)"`
+	Matches   []provider.SourceMatch `json:"matches" jsonschema:"Matching lines"`
+}
+
+func ReadEngineFile(ctx context.Context, req *mcp.CallToolRequest, args ReadEngineFileParams) (*mcp.CallToolResult, *provider.SourceSnippet, error) {
+	src, err := getEngineSource()
+	if err != nil {
+		return nil, nil, err
+	}
+	snippet, err := src.ReadFile(args.Path, args.StartLine, args.EndLine)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &snippet, nil
+}
+
+func GetEngineSnippet(ctx context.Context, req *mcp.CallToolRequest, args GetEngineSnippetParams) (*mcp.CallToolResult, *EngineSnippetOutput, error) {
+	src, err := getEngineSource()
+	if err != nil {
+		return nil, nil, err
+	}
+	file, line, err := parseLocation(args.Location)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := args.Context
+	if c <= 0 {
+		c = DefaultSnippetContext
+	}
+	snippet, err := src.ReadFile(file, max(line-c, 1), line+c)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &EngineSnippetOutput{
+		Location: args.Location,
+		Line:     line,
+		Snippet:  snippet,
+	}, nil
+}
+
+func ListEngineDir(ctx context.Context, req *mcp.CallToolRequest, args ListEngineDirParams) (*mcp.CallToolResult, *EngineDirOutput, error) {
+	src, err := getEngineSource()
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := src.ListDir(args.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	page, pageSize := normalizePage(args.Page, args.PageSize)
+	items, remaining, total := paginate(entries, page, pageSize, args.Max)
+	res := &EngineDirOutput{
+		Path:      args.Path,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Entries:   items,
+	}
+	return nil, res, nil
+}
+
+func GrepEngine(ctx context.Context, req *mcp.CallToolRequest, args GrepEngineParams) (*mcp.CallToolResult, *GrepEngineOutput, error) {
+	src, err := getEngineSource()
+	if err != nil {
+		return nil, nil, err
+	}
+	matches, err := src.Grep(args.Pattern, args.Dir, args.Max)
+	if err != nil {
+		return nil, nil, err
+	}
+	page, pageSize := normalizePage(args.Page, args.PageSize)
+	items, remaining, total := paginate(matches, page, pageSize, 0)
+	res := &GrepEngineOutput{
+		Pattern:   args.Pattern,
+		Dir:       args.Dir,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Matches:   items,
+	}
+	return nil, res, nil
+}
+
+func AddEngineTools(server *mcp.Server) {
+	addTool(server, &mcp.Tool{
+		Name:        "ReadEngineFile",
+		Description: "Read a file or a line range from the engine repository",
+	}, ReadEngineFile)
+
+	addTool(server, &mcp.Tool{
+		Name:        "GetEngineSnippet",
+		Description: "Get the engine source around a panic location (file:line[:column])",
+	}, GetEngineSnippet)
+
+	addTool(server, &mcp.Tool{
+		Name:        "ListEngineDir",
+		Description: "List a directory in the engine repository (paginated)",
+	}, ListEngineDir)
+
+	addTool(server, &mcp.Tool{
+		Name:        "GrepEngine",
+		Description: "Search the engine repository with a regular expression (paginated)",
+	}, GrepEngine)
+}
+
+// parseLocation splits file:line[:column] into the file and line.
+func parseLocation(loc string) (string, int, error) {
+	parts := strings.Split(strings.TrimSpace(loc), ":")
+	if len(parts) < 2 {
+		return "", 0, fmt.Errorf("invalid location %q, expected file:line[:column]", loc)
+	}
+
+	if len(parts) > 2 {
+		if _, err := strconv.Atoi(parts[len(parts)-2]); err == nil {
+			parts = parts[:len(parts)-1]
+		}
+	}
+
+	line, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil || line <= 0 {
+		return "", 0, fmt.Errorf("invalid line in location %q", loc)
+	}
+
+	return strings.Join(parts[:len(parts)-1], ":"), line, nil
+}
+
+func getEngineSource() (provider.EngineSourceProvider, error) {
+	if provider.EngineSource == nil {
+		return nil, errors.New("engine source provider not set")
+	}
+	return provider.EngineSource, nil
+}
@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// READ_ONLY_DENIED are the tools hidden in read-only mode, they edit code, start runs or change server state.
+var READ_ONLY_DENIED = []string{
+	"SetTestCode",
+	"SetHarnessCode",
+	"PatchTestCode",
+	"PatchHarnessCode",
+	"ResetEdits",
+	"Rerun*",
+	"RunSnippet",
+	"MinimizeTest",
+	"LoadBaseline",
+	"DeleteWorkspace",
+}
+
+// Policy decides which tools are listed and callable. Allow and Deny hold tool names or
+// path.Match patterns (e.g. "Rerun*"); an empty Allow allows every tool not denied.
+type Policy struct {
+	ReadOnly bool
+	Allow    []string
+	Deny     []string
+}
+
+func (p Policy) Allows(tool string) bool {
+	if len(p.Allow) > 0 && !matchAny(p.Allow, tool) {
+		return false
+	}
+
+	if matchAny(p.Deny, tool) {
+		return false
+	}
+
+	return !p.ReadOnly || !matchAny(READ_ONLY_DENIED, tool)
+}
+
+// RESOURCE_TOOLS maps resource URI prefixes to the tools serving the same data, a resource is only listed,
+// readable and subscribable if all of them are allowed. Longer prefixes come first.
+var RESOURCE_TOOLS = []struct {
+	Prefix string
+	Tools  []string
+}{
+	{TEST_URI, []string{"GetTestCode"}},
+	{HARNESS_URI, []string{"GetHarnessCode"}},
+	{HARNESS_LIST_URI, []string{"GetHaressFiles"}},
+	{SECTION_URI, []string{"GetSpec"}},
+	{RESULTS_URI, []string{"GetTestStatus", "GetTestOutput"}},
+	{RESULTS_DIR_URI, []string{"GetTestsInDir", "GetTestStatusesInDir"}},
+}
+
+// PROMPT_TOOLS maps the prompts to the tools whose data they include, a prompt is only listed and
+// retrievable if all of them are allowed.
+var PROMPT_TOOLS = map[string][]string{
+	"triage-failing-test":         {"GetTestCode", "GetHarnessCode", "GetTestStatus", "GetTestOutput", "GetEngineSnippet", "GetSpec"},
+	"fix-not-implemented-cluster": {"RankPanicLocations", "GetEngineSnippet", "GetTestsWithStatusInDirRecursive", "GetTestCode"},
+	"explain-intrinsic":           {"SpecForIntrinsic", "SearchDir"},
+	"review-regression-diff":      {"GetTestStatusesInDirRecursive", "GetTestOutput"},
+}
+
+// completionTools returns the tools mirrored by the prompt or resource a completion is for,
+// ok is false for completions of anything else.
+func completionTools(ref *mcp.CompleteReference) ([]string, bool) {
+	if ref == nil {
+		return nil, false
+	}
+
+	switch ref.Type {
+	case "ref/prompt":
+		tools, ok := PROMPT_TOOLS[ref.Name]
+		return tools, ok
+	case "ref/resource":
+		tools := resourceTools(ref.URI)
+		return tools, tools != nil
+	}
+
+	return nil, false
+}
+
+// resourceTools returns the tools mirrored by a resource URI or template.
+func resourceTools(uri string) []string {
+	for _, r := range RESOURCE_TOOLS {
+		if strings.HasPrefix(uri, r.Prefix) {
+			return r.Tools
+		}
+	}
+
+	return nil
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PolicyMiddleware hides tools that one of the policies doesn't allow from tools/list and rejects calls to them.
+// Resources and prompts are hidden and rejected the same way as the tools they mirror, completions of denied
+// resources and prompts are empty.
+func PolicyMiddleware(policies ...Policy) mcp.Middleware {
+	allows := func(tools ...string) bool {
+		for _, tool := range tools {
+			for _, p := range policies {
+				if !p.Allows(tool) {
+					return false
+				}
+			}
+		}
+
+		return true
+	}
+
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			switch r := req.(type) {
+			case *mcp.CallToolRequest:
+				if !allows(r.Params.Name) {
+					return nil, fmt.Errorf("tool %s is not permitted", r.Params.Name)
+				}
+			case *mcp.ReadResourceRequest:
+				if !allows(resourceTools(r.Params.URI)...) {
+					return nil, fmt.Errorf("resource %s is not permitted", r.Params.URI)
+				}
+			case *mcp.SubscribeRequest:
+				if !allows(resourceTools(r.Params.URI)...) {
+					return nil, fmt.Errorf("resource %s is not permitted", r.Params.URI)
+				}
+			case *mcp.GetPromptRequest:
+				if !allows(PROMPT_TOOLS[r.Params.Name]...) {
+					return nil, fmt.Errorf("prompt %s is not permitted", r.Params.Name)
+				}
+			case *mcp.CompleteRequest:
+				if tools, ok := completionTools(r.Params.Ref); !ok || !allows(tools...) {
+					return &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: []string{}}}, nil
+				}
+			}
+
+			res, err := next(ctx, method, req)
+			if err != nil {
+				return res, err
+			}
+
+			switch list := res.(type) {
+			case *mcp.ListToolsResult:
+				allowed := make([]*mcp.Tool, 0, len(list.Tools))
+				for _, t := range list.Tools {
+					if allows(t.Name) {
+						allowed = append(allowed, t)
+					}
+				}
+
+				list.Tools = allowed
+			case *mcp.ListResourcesResult:
+				allowed := make([]*mcp.Resource, 0, len(list.Resources))
+				for _, r := range list.Resources {
+					if allows(resourceTools(r.URI)...) {
+						allowed = append(allowed, r)
+					}
+				}
+
+				list.Resources = allowed
+			case *mcp.ListResourceTemplatesResult:
+				allowed := make([]*mcp.ResourceTemplate, 0, len(list.ResourceTemplates))
+				for _, t := range list.ResourceTemplates {
+					if allows(resourceTools(t.URITemplate)...) {
+						allowed = append(allowed, t)
+					}
+				}
+
+				list.ResourceTemplates = allowed
+			case *mcp.ListPromptsResult:
+				allowed := make([]*mcp.Prompt, 0, len(list.Prompts))
+				for _, p := range list.Prompts {
+					if allows(PROMPT_TOOLS[p.Name]...) {
+						allowed = append(allowed, p)
+					}
+				}
+
+				list.Prompts = allowed
+			}
+
+			return res, nil
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Sharktheone/mcp262/runner/panics"
+	"github.com/Sharktheone/mcp262/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MAX_PROMPT_SECTION caps every piece of content included in a prompt.
+const MAX_PROMPT_SECTION = 20000
+
+// MAX_PROMPT_TESTS is the number of tests listed in a prompt, MAX_PROMPT_SAMPLES the number included with code.
+const MAX_PROMPT_TESTS = 50
+const MAX_PROMPT_SAMPLES = 3
+
+var includesRe = regexp.MustCompile(`(?m)^includes:\s*\[([^\]]*)\]`)
+var includesListRe = regexp.MustCompile(`(?m)^includes:\s*\n((?:\s+-\s*\S+\s*\n)+)`)
+var esidRe = regexp.MustCompile(`(?m)^esid:\s*(\S+)`)
+
+func AddPrompts(server *mcp.Server) {
+	server.AddPrompt(&mcp.Prompt{
+		Name:        "triage-failing-test",
+		Title:       "Triage a failing test",
+		Description: "Find out why a test fails, with its code, harness files, output, panic location and spec section",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "test_path", Description: "Path of the test, e.g. built-ins/Array/prototype/flatMap/depth-always-one.js", Required: true},
+		},
+	}, TriageFailingTest)
+
+	server.AddPrompt(&mcp.Prompt{
+		Name:        "fix-not-implemented-cluster",
+		Title:       "Fix a NOT_IMPLEMENTED cluster",
+		Description: "Implement the engine feature blocking the most tests in a directory, with the ranked panic locations and sample tests",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "dir", Description: "Directory of the tests, e.g. built-ins/Temporal", Required: true},
+			{Name: "status", Description: "Status of the cluster, defaults to NOT_IMPLEMENTED"},
+		},
+	}, FixNotImplementedCluster)
+
+	server.AddPrompt(&mcp.Prompt{
+		Name:        "explain-intrinsic",
+		Title:       "Explain the spec section for an intrinsic",
+		Description: "Explain the specification of an intrinsic and where it is tested",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "intrinsic", Description: "Intrinsic, e.g. %Array.prototype.flatMap%", Required: true},
+		},
+	}, ExplainIntrinsic)
+
+	server.AddPrompt(&mcp.Prompt{
+		Name:        "review-regression-diff",
+		Title:       "Review a regression diff",
+		Description: "Review the status changes of the latest local run in a directory against the baseline",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "dir", Description: "Directory that was rerun, e.g. built-ins/Array", Required: true},
+		},
+	}, ReviewRegressionDiff)
+}
+
+// promptBuilder collects the sections of a prompt, content that can't be loaded is noted instead of failing the prompt.
+type promptBuilder struct {
+	sb strings.Builder
+}
+
+func (b *promptBuilder) text(format string, args ...any) {
+	fmt.Fprintf(&b.sb, format, args...)
+	b.sb.WriteString("\n\n")
+}
+
+func (b *promptBuilder) section(title string, lang string, content string, err error) {
+	fmt.Fprintf(&b.sb, "## %s\n", title)
+
+	if err != nil {
+		fmt.Fprintf(&b.sb, "(unavailable: %v)\n\n", err)
+		return
+	}
+
+	if len(content) > MAX_PROMPT_SECTION {
+		content = content[:MAX_PROMPT_SECTION] + "\n... (truncated)"
+	}
+
+	fmt.Fprintf(&b.sb, "```%s\n%s\n```\n\n", lang, strings.TrimRight(content, "\n"))
+}
+
+func (b *promptBuilder) result(description string) *mcp.GetPromptResult {
+	return &mcp.GetPromptResult{
+		Description: description,
+		Messages: []*mcp.PromptMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: b.sb.String()}},
+		},
+	}
+}
+
+func promptArgument(req *mcp.GetPromptRequest, name string) (string, error) {
+	v := strings.TrimSpace(req.Params.Arguments[name])
+	if v == "" {
+		return "", errors.New("missing argument " + name)
+	}
+
+	return v, nil
+}
+
+func TriageFailingTest(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	arg, err := promptArgument(req, "test_path")
+	if err != nil {
+		return nil, err
+	}
+
+	p := utils.ResolvePath(arg)
+	if !strings.HasSuffix(p, ".js") {
+		p += ".js"
+	}
+
+	var b promptBuilder
+	b.text("Triage the failing test262 test `%s`. Determine whether the engine, the harness or the test is at fault, "+
+		"point to the engine code responsible and propose a fix. Use the tools to read more engine code or rerun the test.", p)
+
+	code, codeErr := "", errors.New("code provider not set")
+	if pv, err := getCodeProvider(req.Session); err == nil {
+		code, codeErr = pv.GetTestCode(p)
+		b.section("Test "+p, "js", code, codeErr)
+
+		for _, h := range testIncludes(code) {
+			hc, err := pv.GetHarnessCode(h)
+			b.section("Harness "+h, "js", hc, err)
+		}
+	} else {
+		b.section("Test "+p, "js", "", codeErr)
+	}
+
+	if prov, err := getProvider(); err == nil {
+		out, status, err := prov.GetTestOutput(p)
+		if status == "" {
+			status, _ = prov.GetTestStatus(p)
+		}
+
+		b.text("Status: %s", status)
+		b.section("Output", "", out, err)
+
+		if loc := panics.Parse(out); loc != nil {
+			snippet, err := engineSnippet(loc.File, loc.Line)
+			b.section(fmt.Sprintf("Engine source at %s:%d (%s)", loc.File, loc.Line, loc.Message), "rust", snippet, err)
+		}
+	}
+
+	if m := esidRe.FindStringSubmatch(code); m != nil {
+		if sp, err := getSpecProvider(); err == nil {
+			spec, err := sp.GetSpec(strings.TrimPrefix(m[1], "sec-"))
+			b.section("Spec section "+m[1], "html", spec, err)
+		}
+	}
+
+	return b.result("Triage " + p), nil
+}
+
+func FixNotImplementedCluster(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	arg, err := promptArgument(req, "dir")
+	if err != nil {
+		return nil, err
+	}
+
+	dir := utils.ResolvePath(arg)
+
+	status := strings.ToUpper(strings.TrimSpace(req.Params.Arguments["status"]))
+	if status == "" {
+		status = "NOT_IMPLEMENTED"
+	}
+
+	var b promptBuilder
+	b.text("Implement the missing engine functionality behind the %s tests in `%s`. "+
+		"Start with the location blocking the most tests, implement it following the spec and rerun the tests to verify.", status, dir)
+
+	var tests []string
+	heading := status + " tests"
+	if r, err := getRunner(); err == nil {
+		if ranked, unlocated, err := r.RankPanicLocations(dir, status); err == nil && len(ranked) > 0 {
+			lines := make([]string, 0, len(ranked)+1)
+			for _, rl := range ranked {
+				lines = append(lines, fmt.Sprintf("%4d  %s:%d  %s", rl.Count, rl.File, rl.Line, rl.Message))
+			}
+			if unlocated > 0 {
+				lines = append(lines, fmt.Sprintf("%4d  (no panic location)", unlocated))
+			}
+			b.section("Panic locations by number of blocked tests", "", strings.Join(lines, "\n"), nil)
+
+			top := ranked[0]
+			snippet, err := engineSnippet(top.File, top.Line)
+			b.section(fmt.Sprintf("Engine source at %s:%d", top.File, top.Line), "rust", snippet, err)
+
+			tests = top.Tests
+			heading = fmt.Sprintf("%s tests blocked by %s:%d", status, top.File, top.Line)
+		} else if err != nil {
+			b.text("No ranked panic locations (%v), the tests below are from the CI results.", err)
+		}
+	}
+
+	if len(tests) == 0 {
+		prov, err := getProvider()
+		if err != nil {
+			return nil, err
+		}
+
+		tests, err = prov.GetTestsWithStatusInDirRec(dir, status)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(tests) == 0 {
+		b.text("There are no %s tests in `%s`.", status, dir)
+		return b.result(fmt.Sprintf("Fix %s tests in %s", status, dir)), nil
+	}
+
+	sort.Strings(tests)
+
+	listed := tests
+	if len(listed) > MAX_PROMPT_TESTS {
+		listed = listed[:MAX_PROMPT_TESTS]
+	}
+	b.section(fmt.Sprintf("%d %s", len(tests), heading), "", strings.Join(listed, "\n"), nil)
+
+	if pv, err := getCodeProvider(req.Session); err == nil {
+		for i, t := range tests {
+			if i == MAX_PROMPT_SAMPLES {
+				break
+			}
+
+			code, err := pv.GetTestCode(t)
+			b.section("Sample test "+t, "js", code, err)
+		}
+	}
+
+	return b.result(fmt.Sprintf("Fix %s tests in %s", status, dir)), nil
+}
+
+func ExplainIntrinsic(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	intrinsic, err := promptArgument(req, "intrinsic")
+	if err != nil {
+		return nil, err
+	}
+
+	sp, err := getSpecProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	var b promptBuilder
+	b.text("Explain the specification of `%s`: the algorithm step by step, the abstract operations it relies on "+
+		"and the observable edge cases an engine implementation has to get right.", intrinsic)
+
+	spec, err := sp.SpecForIntrinsic(intrinsic)
+	b.section("Spec "+intrinsic, "html", spec, err)
+
+	if prov, err := getProvider(); err == nil {
+		name := strings.ReplaceAll(strings.Trim(intrinsic, "%"), ".", "/")
+		if dirs, err := prov.SearchDir(name); err == nil && len(dirs) > 0 {
+			b.section("Test directories", "", strings.Join(dirs, "\n"), nil)
+		}
+	}
+
+	return b.result("Explain " + intrinsic), nil
+}
+
+func ReviewRegressionDiff(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	arg, err := promptArgument(req, "dir")
+	if err != nil {
+		return nil, err
+	}
+
+	dir := utils.ResolvePath(arg)
+
+	r, err := getRunner()
+	if err != nil {
+		return nil, err
+	}
+
+	diffs, err := r.DiffLastRun(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var b promptBuilder
+	b.text("Review the status changes of the latest local run in `%s` against the baseline. "+
+		"Separate regressions from fixes, group regressions by likely cause and identify the engine change responsible.", dir)
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return len(diffs[i].Items) > len(diffs[j].Items)
+	})
+
+	regressions := make([]string, 0)
+	for _, d := range diffs {
+		items := d.Items
+		if len(items) > MAX_PROMPT_TESTS {
+			items = items[:MAX_PROMPT_TESTS]
+		}
+
+		b.section(fmt.Sprintf("%s -> %s (%d)", d.From, d.To, len(d.Items)), "", strings.Join(items, "\n"), nil)
+
+		if d.From == "PASS" {
+			regressions = append(regressions, d.Items...)
+		}
+	}
+
+	if len(diffs) == 0 {
+		b.text("No status changed.")
+	}
+
+	if prov, err := getProvider(); err == nil {
+		for i, t := range regressions {
+			if i == MAX_PROMPT_SAMPLES {
+				break
+			}
+
+			out, _, err := prov.GetTestOutput(t)
+			b.section("Output of regressed test "+t, "", out, err)
+		}
+	}
+
+	return b.result("Review regressions in " + dir), nil
+}
+
+// testIncludes returns the harness files a test includes besides the default ones (assert.js, sta.js).
+func testIncludes(code string) []string {
+	var includes []string
+
+	if m := includesRe.FindStringSubmatch(code); m != nil {
+		for _, f := range strings.Split(m[1], ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				includes = append(includes, f)
+			}
+		}
+	} else if m := includesListRe.FindStringSubmatch(code); m != nil {
+		for _, line := range strings.Split(m[1], "\n") {
+			if f := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "-")); f != "" {
+				includes = append(includes, f)
+			}
+		}
+	}
+
+	return includes
+}
+
+func engineSnippet(file string, line int) (string, error) {
+	src, err := getEngineSource()
+	if err != nil {
+		return "", err
+	}
+
+	snippet, err := src.ReadFile(file, max(line-DefaultSnippetContext, 1), line+DefaultSnippetContext)
+	if err != nil {
+		return "", err
+	}
+
+	return snippet.Code, nil
+}
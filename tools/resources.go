@@ -0,0 +1,305 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Sharktheone/mcp262/provider"
+	"github.com/Sharktheone/mcp262/runner/panics"
+	"github.com/Sharktheone/mcp262/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	TEST_URI         = "test262://test/"
+	HARNESS_URI      = "test262://harness/"
+	HARNESS_LIST_URI = "test262://harness"
+	SECTION_URI      = "ecma262://section/"
+	RESULTS_URI      = "results://test/"
+	RESULTS_DIR_URI  = "results://dir/"
+)
+
+const (
+	MIME_JAVASCRIPT = "text/javascript"
+	MIME_HTML       = "text/html"
+	MIME_JSON       = "application/json"
+)
+
+type DirListing struct {
+	Path        string         `json:"path"`
+	URI         string         `json:"uri"`
+	Directories []DirEntry     `json:"directories"`
+	Tests       []DirTestEntry `json:"tests"`
+}
+
+type DirEntry struct {
+	Path string `json:"path"`
+	URI  string `json:"uri"`
+}
+
+type DirTestEntry struct {
+	Path       string `json:"path"`
+	Status     string `json:"status"`
+	CodeURI    string `json:"code_uri"`
+	ResultsURI string `json:"results_uri"`
+}
+
+// AddResources registers the resources, the server needs Subscribe and Unsubscribe
+// as handlers to support subscriptions to results:// resources.
+func AddResources(server *mcp.Server) {
+	addSubscriptionServer(server)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "test",
+		Title:       "Test262 test",
+		Description: "Source code of a test, e.g. test262://test/built-ins/Array/length.js",
+		URITemplate: TEST_URI + "{+path}",
+		MIMEType:    MIME_JAVASCRIPT,
+	}, readTest)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "harness",
+		Title:       "Test262 harness file",
+		Description: "Source code of a harness file, e.g. test262://harness/assert.js",
+		URITemplate: HARNESS_URI + "{+file}",
+		MIMEType:    MIME_JAVASCRIPT,
+	}, readHarness)
+
+	server.AddResource(&mcp.Resource{
+		Name:        "harness-files",
+		Title:       "Test262 harness files",
+		Description: "List of the harness files",
+		URI:         HARNESS_LIST_URI,
+		MIMEType:    MIME_JSON,
+	}, readHarnessList)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "section",
+		Title:       "ECMAScript spec section",
+		Description: "HTML of a spec section by id, e.g. ecma262://section/array.prototype.map",
+		URITemplate: SECTION_URI + "{id}",
+		MIMEType:    MIME_HTML,
+	}, readSection)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "result",
+		Title:       "Test result",
+		Description: "Status and output of a test " + resultsSource,
+		URITemplate: RESULTS_URI + "{+path}",
+		MIMEType:    MIME_JSON,
+	}, readResult)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "directory",
+		Title:       "Test directory",
+		Description: "Subdirectories and tests (with status) of a directory, e.g. results://dir/built-ins/Array " + resultsSource,
+		URITemplate: RESULTS_DIR_URI + "{+path}",
+		MIMEType:    MIME_JSON,
+	}, readDir)
+
+	server.AddResource(&mcp.Resource{
+		Name:        "root-directory",
+		Title:       "Test root directory",
+		Description: "Top level directories of the test suite",
+		URI:         RESULTS_DIR_URI,
+		MIMEType:    MIME_JSON,
+	}, readDir)
+}
+
+// resourcePath returns the unescaped part of uri after prefix.
+func resourcePath(uri string, prefix string) (string, error) {
+	p, ok := strings.CutPrefix(uri, prefix)
+	if !ok {
+		return "", mcp.ResourceNotFoundError(uri)
+	}
+
+	return url.PathUnescape(p)
+}
+
+func textResource(uri string, text string) *mcp.ReadResourceResult {
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: uri, Text: text}},
+	}
+}
+
+func jsonResource(uri string, v any) (*mcp.ReadResourceResult, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return textResource(uri, string(b)), nil
+}
+
+func readTest(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	pv, err := getCodeProvider(req.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := resourcePath(req.Params.URI, TEST_URI)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := pv.GetTestCode(utils.ResolvePath(p))
+	if err != nil {
+		return nil, err
+	}
+
+	return textResource(req.Params.URI, code), nil
+}
+
+func readHarness(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	pv, err := getCodeProvider(req.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := resourcePath(req.Params.URI, HARNESS_URI)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := pv.GetHarnessCode(harnessPath(file))
+	if err != nil {
+		return nil, err
+	}
+
+	return textResource(req.Params.URI, code), nil
+}
+
+func readHarnessList(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	pv, err := getCodeProvider(req.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := pv.GetHaressFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]DirEntry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, DirEntry{Path: f, URI: HARNESS_URI + f})
+	}
+
+	return jsonResource(req.Params.URI, entries)
+}
+
+func readSection(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	sp, err := getSpecProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := resourcePath(req.Params.URI, SECTION_URI)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := sp.GetSpec(strings.TrimPrefix(id, "sec-"))
+	if err != nil {
+		return nil, err
+	}
+
+	return textResource(req.Params.URI, content), nil
+}
+
+func readResult(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	prov, err := getProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := resourcePath(req.Params.URI, RESULTS_URI)
+	if err != nil {
+		return nil, err
+	}
+
+	p = utils.ResolvePath(p)
+
+	status, err := prov.GetTestStatus(p)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+
+	res := map[string]any{"test_path": p, "status": status}
+
+	if sp, ok := prov.(provider.TestSourceProvider); ok {
+		if source, _, err := sp.GetTestSource(p); err == nil {
+			res["source"] = source
+		}
+	}
+
+	if out, _, err := prov.GetTestOutput(p); err == nil {
+		res["output"] = out
+		if loc := panics.Parse(out); loc != nil {
+			res["panic"] = loc
+		}
+	}
+
+	return jsonResource(req.Params.URI, res)
+}
+
+func readDir(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	prov, err := getProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := resourcePath(req.Params.URI, RESULTS_DIR_URI)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := utils.ResolvePath(strings.TrimSuffix(p, "/"))
+
+	statuses, err := prov.GetTestStatusesInDir(dir)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+
+	entries, err := prov.GetTestsInDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	listing := DirListing{
+		Path:        dir,
+		URI:         RESULTS_DIR_URI + dir,
+		Directories: make([]DirEntry, 0),
+		Tests:       make([]DirTestEntry, 0, len(statuses)),
+	}
+
+	for _, e := range entries {
+		if _, isTest := statuses[e]; isTest {
+			continue
+		}
+
+		listing.Directories = append(listing.Directories, DirEntry{Path: e, URI: RESULTS_DIR_URI + e})
+	}
+
+	for t, s := range statuses {
+		listing.Tests = append(listing.Tests, DirTestEntry{
+			Path:       t,
+			Status:     s,
+			CodeURI:    TEST_URI + t,
+			ResultsURI: RESULTS_URI + t,
+		})
+	}
+
+	sort.Slice(listing.Directories, func(i, j int) bool {
+		return listing.Directories[i].Path < listing.Directories[j].Path
+	})
+
+	sort.Slice(listing.Tests, func(i, j int) bool {
+		return listing.Tests[i].Path < listing.Tests[j].Path
+	})
+
+	return jsonResource(req.Params.URI, listing)
+}
@@ -3,6 +3,8 @@ package tools
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/Sharktheone/mcp262/provider"
 	"github.com/Sharktheone/mcp262/utils"
@@ -24,67 +26,342 @@ type RerunFailedTestsInDirParams struct {
 	Rebuild bool   `json:"rebuild" jsonschema:"Whether to rebuild before running the tests"`
 }
 
-func RerunTest(ctx context.Context, req *mcp.CallToolRequest, args RerunTestParams) (*mcp.CallToolResult, any, error) {
-	runner, err := getRunner()
+type RunSnippetParams struct {
+	Source   string   `json:"source" jsonschema:"JavaScript source to run; a /*--- ---*/ frontmatter in the source is used as is"`
+	Includes []string `json:"includes,omitempty" jsonschema:"Harness files to include besides assert.js and sta.js (e.g. compareArray.js)"`
+	Flags    []string `json:"flags,omitempty" jsonschema:"test262 flags: module, strict, noStrict, async or raw"`
+	Timeout  int      `json:"timeout,omitempty" jsonschema:"Timeout in seconds; defaults to the test timeout (30s), at most 300s"`
+	Rebuild  bool     `json:"rebuild" jsonschema:"Whether to rebuild before running the snippet"`
+}
+
+type MinimizeTestParams struct {
+	TestPath string `json:"test_path" jsonschema:"Path to the single failing test file (e.g. /test262/test/language/...)"`
+	MaxRuns  int    `json:"max_runs,omitempty" jsonschema:"Maximum number of engine runs; defaults to 500"`
+	Rebuild  bool   `json:"rebuild" jsonschema:"Whether to rebuild before minimizing"`
+	Apply    bool   `json:"apply" jsonschema:"Replace the test in the edit workspace of this session with the minimized code"`
+}
+
+type RankPanicLocationsParams struct {
+	Dir      string `json:"dir" jsonschema:"Directory path to rank panic locations in (results from the last local run)"`
+	Status   string `json:"status" jsonschema:"Optional status to restrict to (NOT_IMPLEMENTED or CRASH); defaults to both"`
+	Page     int    `json:"page" jsonschema:"Page number starting from 1; defaults to 1"`
+	PageSize int    `json:"page_size" jsonschema:"Items per page; defaults to DefaultPageSize if omitted"`
+	Max      int    `json:"max" jsonschema:"Optional global maximum number of items to include across all pages; 0 means no limit"`
+}
+
+type ExportResultsParams struct {
+	Format string `json:"format" jsonschema:"Export format: junit, tap or csv"`
+	Dir    string `json:"dir" jsonschema:"Optional directory to restrict the export to; defaults to all local results"`
+	Write  bool   `json:"write" jsonschema:"Write the export to the configured output directory instead of returning the content"`
+}
+
+type LoadBaselineParams struct {
+	Format string `json:"format" jsonschema:"Format of the results: ci, results, test262-harness, test262.fyi or csv"`
+	Path   string `json:"path" jsonschema:"Path of the results file (or data directory for test262.fyi) relative to the baseline directory of the server"`
+	Engine string `json:"engine" jsonschema:"Engine to import for formats containing multiple engines (test262.fyi)"`
+}
+
+type RerunTestOutput struct {
+	TestResult *provider.TestResult  `json:"test_result,omitempty" jsonschema:"Result of the test, missing if the build failed"`
+	Error      string                `json:"error,omitempty" jsonschema:"Set if rebuilding the engine failed"`
+	Build      *provider.BuildStatus `json:"build,omitempty" jsonschema:"Status and diagnostics of the failed build"`
+}
+
+type RerunOutput struct {
+	Results []provider.TestDiff   `json:"results,omitempty" jsonschema:"Status changes against the baseline (FROM -> TO), missing if the build failed"`
+	Error   string                `json:"error,omitempty" jsonschema:"Set if rebuilding the engine failed"`
+	Build   *provider.BuildStatus `json:"build,omitempty" jsonschema:"Status and diagnostics of the failed build"`
+}
+
+type RunSnippetOutput struct {
+	Result *provider.SnippetResult `json:"result,omitempty" jsonschema:"Status, output, duration and peak memory of the run, missing if the build failed"`
+	Error  string                  `json:"error,omitempty" jsonschema:"Set if rebuilding the engine failed"`
+	Build  *provider.BuildStatus   `json:"build,omitempty" jsonschema:"Status and diagnostics of the failed build"`
+}
+
+type MinimizeTestOutput struct {
+	Result  *provider.MinimizedTest `json:"result,omitempty" jsonschema:"Minimized test: failure signature (status and panic location or first output line) kept, reduced code, line and statement counts, number of runs and whether it finished within max_runs; missing if the build failed"`
+	Applied bool                    `json:"applied,omitempty" jsonschema:"Whether the minimized code was written to the edit workspace"`
+	Error   string                  `json:"error,omitempty" jsonschema:"Set if rebuilding the engine failed"`
+	Build   *provider.BuildStatus   `json:"build,omitempty" jsonschema:"Status and diagnostics of the failed build"`
+}
+
+type RankedLocationsOutput struct {
+	Dir       string                         `json:"dir" jsonschema:"Directory as requested"`
+	Page      int                            `json:"page" jsonschema:"Page number starting from 1"`
+	PageSize  int                            `json:"page_size" jsonschema:"Items per page"`
+	Returned  int                            `json:"returned" jsonschema:"Number of items on this page"`
+	Remaining int                            `json:"remaining" jsonschema:"Number of items after this page (limited by max)"`
+	Total     int                            `json:"total" jsonschema:"Number of items across all pages"`
+	Locations []provider.RankedPanicLocation `json:"locations" jsonschema:"Panic locations, the one blocking the most tests first"`
+	Unlocated int                            `json:"unlocated" jsonschema:"Number of matching results without a panic location, not included in the ranking"`
+}
+
+type LastBuildOutput struct {
+	Build *provider.BuildStatus `json:"build" jsonschema:"Status and diagnostics of the last build"`
+}
+
+type BuildsOutput struct {
+	Builds []provider.BuildStatus `json:"builds" jsonschema:"Latest build per profile"`
+}
+
+type LoadBaselineOutput struct {
+	Path     string `json:"path" jsonschema:"Path as requested"`
+	Format   string `json:"format" jsonschema:"Format as requested"`
+	NumTests int    `json:"num_tests" jsonschema:"Number of tests in the baseline"`
+}
+
+func RerunTest(ctx context.Context, req *mcp.CallToolRequest, args RerunTestParams) (*mcp.CallToolResult, *RerunTestOutput, error) {
+	runner, err := getSessionRunner(req.Session)
 	if err != nil {
 		return nil, nil, err
 	}
 	p := utils.ResolvePath(args.TestPath)
 	result, err := runner.RerunTest(p, args.Rebuild)
+	if err != nil {
+		return respondRunnerError[RerunTestOutput](err)
+	}
+	return nil, &RerunTestOutput{TestResult: &result}, nil
+}
+
+func RerunTestsInDir(ctx context.Context, req *mcp.CallToolRequest, args RerunTestsInDirParams) (*mcp.CallToolResult, *RerunOutput, error) {
+	runner, err := getSessionRunner(req.Session)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := utils.ResolvePath(args.Dir)
+	results, err := runner.RerunTestsInDirChanges(p, args.Rebuild)
+	if err != nil {
+		return respondRunnerError[RerunOutput](err)
+	}
+
+	return nil, &RerunOutput{Results: results}, nil
+}
+
+func RerunFailedTestsInDir(ctx context.Context, req *mcp.CallToolRequest, args RerunFailedTestsInDirParams) (*mcp.CallToolResult, *RerunOutput, error) {
+	runner, err := getSessionRunner(req.Session)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := utils.ResolvePath(args.Dir)
+	results, err := runner.RerunFailedTestsInDirChanges(p, args.Rebuild)
+	if err != nil {
+		return respondRunnerError[RerunOutput](err)
+	}
+	return nil, &RerunOutput{Results: results}, nil
+}
+
+func RunSnippet(ctx context.Context, req *mcp.CallToolRequest, args RunSnippetParams) (*mcp.CallToolResult, *RunSnippetOutput, error) {
+	runner, err := getSessionRunner(req.Session)
 	if err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{
-		"test_result": result,
-	}), nil, nil
+	sr, ok := runner.(provider.SnippetRunner)
+	if !ok {
+		return nil, nil, errors.New("runner can't run snippets")
+	}
+	result, err := sr.RunSnippet(provider.Snippet{
+		Source:   args.Source,
+		Includes: args.Includes,
+		Flags:    args.Flags,
+		Timeout:  time.Duration(args.Timeout) * time.Second,
+		Rebuild:  args.Rebuild,
+	})
+	if err != nil {
+		return respondRunnerError[RunSnippetOutput](err)
+	}
+	return nil, &RunSnippetOutput{Result: &result}, nil
+}
+
+func MinimizeTest(ctx context.Context, req *mcp.CallToolRequest, args MinimizeTestParams) (*mcp.CallToolResult, *MinimizeTestOutput, error) {
+	runner, err := getSessionRunner(req.Session)
+	if err != nil {
+		return nil, nil, err
+	}
+	tm, ok := runner.(provider.TestMinimizer)
+	if !ok {
+		return nil, nil, errors.New("runner can't minimize tests")
+	}
+	p := utils.ResolvePath(args.TestPath)
+	if !strings.HasSuffix(p, ".js") {
+		p += ".js"
+	}
+	result, err := tm.MinimizeTest(p, args.Rebuild, args.MaxRuns)
+	if err != nil {
+		return respondRunnerError[MinimizeTestOutput](err)
+	}
+	out := &MinimizeTestOutput{Result: &result}
+	if args.Apply {
+		pv, err := getCodeProvider(req.Session)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := pv.SetTestCode(p, result.Code); err != nil {
+			return nil, nil, err
+		}
+		out.Applied = true
+	}
+	return nil, out, nil
 }
 
-func RerunTestsInDir(ctx context.Context, req *mcp.CallToolRequest, args RerunTestsInDirParams) (*mcp.CallToolResult, any, error) {
+func RankPanicLocations(ctx context.Context, req *mcp.CallToolRequest, args RankPanicLocationsParams) (*mcp.CallToolResult, *RankedLocationsOutput, error) {
 	runner, err := getRunner()
 	if err != nil {
 		return nil, nil, err
 	}
 	p := utils.ResolvePath(args.Dir)
-	results, err := runner.RerunTestsInDirChanges(p, args.Rebuild)
+	locations, unlocated, err := runner.RankPanicLocations(p, args.Status)
+	if err != nil {
+		return nil, nil, err
+	}
+	page, pageSize := normalizePage(args.Page, args.PageSize)
+	items, remaining, total := paginate(locations, page, pageSize, args.Max)
+	res := &RankedLocationsOutput{
+		Dir:       args.Dir,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Locations: items,
+		Unlocated: unlocated,
+	}
+	return nil, res, nil
+}
+
+func GetLastBuild(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, *LastBuildOutput, error) {
+	runner, err := getRunner()
+	if err != nil {
+		return nil, nil, err
+	}
+	build, err := runner.LastBuild()
 	if err != nil {
 		return nil, nil, err
 	}
+	return nil, &LastBuildOutput{Build: build}, nil
+}
 
-	return utils.RespondWith(map[string]any{
-		"results": results,
-	}), nil, nil
+func GetBuildStatus(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, *BuildsOutput, error) {
+	runner, err := getRunner()
+	if err != nil {
+		return nil, nil, err
+	}
+	builds, err := runner.Builds()
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &BuildsOutput{Builds: builds}, nil
 }
 
-func RerunFailedTestsInDir(ctx context.Context, req *mcp.CallToolRequest, args RerunFailedTestsInDirParams) (*mcp.CallToolResult, any, error) {
+func ExportResults(ctx context.Context, req *mcp.CallToolRequest, args ExportResultsParams) (*mcp.CallToolResult, *provider.ExportedResults, error) {
 	runner, err := getRunner()
 	if err != nil {
 		return nil, nil, err
 	}
 	p := utils.ResolvePath(args.Dir)
-	results, err := runner.RerunFailedTestsInDirChanges(p, args.Rebuild)
+	exported, err := runner.ExportResults(p, args.Format, args.Write)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &exported, nil
+}
+
+func LoadBaseline(ctx context.Context, req *mcp.CallToolRequest, args LoadBaselineParams) (*mcp.CallToolResult, *LoadBaselineOutput, error) {
+	runner, err := getRunner()
 	if err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{
-		"results": results,
-	}), nil, nil
+	n, err := runner.LoadBaseline(args.Format, args.Path, args.Engine)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &LoadBaselineOutput{Path: args.Path, Format: args.Format, NumTests: n}, nil
 }
 
 func AddRunnerTools(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "RerunTest",
 		Description: "Rerun a single test",
 	}, RerunTest)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "RerunTestsInDir",
 		Description: "Rerun all tests in a directory",
 	}, RerunTestsInDir)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "RerunFailedTestsInDir",
 		Description: "Rerun failed tests in a directory",
 	}, RerunFailedTestsInDir)
+
+	addTool(server, &mcp.Tool{
+		Name:        "RunSnippet",
+		Description: "Run JavaScript source through the engine like a test262 test (with the harness, optional includes and flags) without creating a test file; the session's harness edits are used",
+	}, RunSnippet)
+
+	addTool(server, &mcp.Tool{
+		Name:        "MinimizeTest",
+		Description: "Reduce a failing test to a minimal reproduction: statements and blocks are removed (delta debugging) and rerun while the failure signature stays the same; uses the session's edits",
+	}, MinimizeTest)
+
+	addTool(server, &mcp.Tool{
+		Name:        "RankPanicLocations",
+		Description: "Rank engine source locations (panics / todo!()) by the number of NOT_IMPLEMENTED and CRASH tests they block (paginated) (results from last local run)",
+	}, RankPanicLocations)
+
+	addTool(server, &mcp.Tool{
+		Name:        "GetLastBuild",
+		Description: "Get the status, errors and warnings of the last engine build",
+	}, GetLastBuild)
+
+	addTool(server, &mcp.Tool{
+		Name:        "GetBuildStatus",
+		Description: "Get the state (RUNNING, SUCCEEDED, FAILED, CANCELLED), commit, duration and diagnostics of the latest debug and release engine builds",
+	}, GetBuildStatus)
+
+	addTool(server, &mcp.Tool{
+		Name:        "ExportResults",
+		Description: "Export local run results as JUnit XML (one testsuite per directory), TAP or CSV, either returned or written to the output directory",
+	}, ExportResults)
+
+	addTool(server, &mcp.Tool{
+		Name:        "LoadBaseline",
+		Description: "Load results from another test262 runner (test262-harness JSON, test262.fyi data, path,status CSV, CI or results.json) as the baseline reruns are diffed against; the file has to be in the configured baseline directory and the baseline is shared by all clients",
+	}, LoadBaseline)
+}
+
+type buildFailure interface {
+	setBuildFailure(build *provider.BuildStatus)
+}
+
+func (o *RerunTestOutput) setBuildFailure(build *provider.BuildStatus) {
+	o.Error, o.Build = "engine build failed", build
+}
+
+func (o *RerunOutput) setBuildFailure(build *provider.BuildStatus) {
+	o.Error, o.Build = "engine build failed", build
+}
+
+func (o *RunSnippetOutput) setBuildFailure(build *provider.BuildStatus) {
+	o.Error, o.Build = "engine build failed", build
+}
+
+func (o *MinimizeTestOutput) setBuildFailure(build *provider.BuildStatus) {
+	o.Error, o.Build = "engine build failed", build
+}
+
+// respondRunnerError turns a failed rebuild into a tool result with the compiler diagnostics
+func respondRunnerError[T any, PT interface {
+	*T
+	buildFailure
+}](err error) (*mcp.CallToolResult, PT, error) {
+	var buildErr *provider.BuildError
+	if errors.As(err, &buildErr) {
+		out := PT(new(T))
+		out.setBuildFailure(buildErr.Build)
+		return &mcp.CallToolResult{IsError: true}, out, nil
+	}
+	return nil, nil, err
 }
 
 // helper to validate runner is set
@@ -94,3 +371,20 @@ func getRunner() (provider.TestRunner, error) {
 	}
 	return provider.Runner, nil
 }
+
+// getSessionRunner returns the runner for runs started from a session, which have to see the edits of its workspace.
+func getSessionRunner(session *mcp.ServerSession) (provider.TestRunner, error) {
+	runner, err := getRunner()
+	if err != nil {
+		return nil, err
+	}
+	o := sessionOverlay(session)
+	if o.Empty() {
+		return runner, nil
+	}
+	or, ok := runner.(provider.OverlayRunner)
+	if !ok {
+		return nil, errors.New("the edit workspace of this session has edits and the runner can only run the tests on disk; ResetEdits or switch workspace with UseWorkspace")
+	}
+	return or.WithOverlay(o), nil
+}
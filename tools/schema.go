@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// addTool registers a tool like mcp.AddTool, with the output schema inferred from Out.
+// The SDK doesn't validate structured content yet, so results are validated against the schema here
+// and the structured content is also returned as JSON text for clients that don't read it.
+func addTool[In, Out any](server *mcp.Server, t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) {
+	tool, handler := mcp.ToolFor(t, h)
+
+	if tool.OutputSchema == nil {
+		server.AddTool(tool, handler)
+		return
+	}
+
+	resolved, err := tool.OutputSchema.Resolve(&jsonschema.ResolveOptions{ValidateDefaults: true})
+	if err != nil {
+		panic(fmt.Sprintf("tool %s: output schema: %v", t.Name, err))
+	}
+
+	server.AddTool(tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		res, err := handler(ctx, req)
+		if err != nil || res.StructuredContent == nil {
+			return res, err
+		}
+
+		b, err := json.Marshal(res.StructuredContent)
+		if err != nil {
+			return nil, err
+		}
+
+		var v any
+		if err := json.Unmarshal(b, &v); err != nil {
+			return nil, err
+		}
+
+		if err := resolved.Validate(v); err != nil {
+			return nil, fmt.Errorf("tool %s returned invalid structured content: %w", t.Name, err)
+		}
+
+		res.StructuredContent = json.RawMessage(b)
+		if len(res.Content) == 0 {
+			res.Content = []mcp.Content{&mcp.TextContent{Text: string(b)}}
+		}
+
+		return res, nil
+	})
+}
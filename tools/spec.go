@@ -33,7 +33,27 @@ type SearchSectionsParams struct {
 	Max      int    `json:"max" jsonschema:"Optional global maximum number of items to include across all pages; 0 means no limit"`
 }
 
-func GetSpec(ctx context.Context, req *mcp.CallToolRequest, args GetSpecParams) (*mcp.CallToolResult, any, error) {
+type SpecOutput struct {
+	SpecPath string `json:"spec_path" jsonschema:"Spec path as requested"`
+	Content  string `json:"content" jsonschema:"HTML of the spec section"`
+}
+
+type IntrinsicSpecOutput struct {
+	Intrinsic string `json:"intrinsic" jsonschema:"Intrinsic as requested"`
+	Section   string `json:"section" jsonschema:"HTML of the spec section of the intrinsic"`
+}
+
+type SectionSearchOutput struct {
+	Query     string   `json:"query" jsonschema:"Search query"`
+	Page      int      `json:"page" jsonschema:"Page number starting from 1"`
+	PageSize  int      `json:"page_size" jsonschema:"Items per page"`
+	Returned  int      `json:"returned" jsonschema:"Number of items on this page"`
+	Remaining int      `json:"remaining" jsonschema:"Number of items after this page (limited by max)"`
+	Total     int      `json:"total" jsonschema:"Number of items across all pages"`
+	Sections  []string `json:"sections" jsonschema:"Matching section ids"`
+}
+
+func GetSpec(ctx context.Context, req *mcp.CallToolRequest, args GetSpecParams) (*mcp.CallToolResult, *SpecOutput, error) {
 	s, err := getSpecProvider()
 	if err != nil {
 		return nil, nil, err
@@ -46,10 +66,10 @@ func GetSpec(ctx context.Context, req *mcp.CallToolRequest, args GetSpecParams)
 	if err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"spec_path": args.SpecPath, "content": content}), nil, nil
+	return nil, &SpecOutput{SpecPath: args.SpecPath, Content: content}, nil
 }
 
-func SpecForIntrinsic(ctx context.Context, req *mcp.CallToolRequest, args SpecForIntrinsicParams) (*mcp.CallToolResult, any, error) {
+func SpecForIntrinsic(ctx context.Context, req *mcp.CallToolRequest, args SpecForIntrinsicParams) (*mcp.CallToolResult, *IntrinsicSpecOutput, error) {
 	s, err := getSpecProvider()
 	if err != nil {
 		return nil, nil, err
@@ -58,10 +78,10 @@ func SpecForIntrinsic(ctx context.Context, req *mcp.CallToolRequest, args SpecFo
 	if err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"intrinsic": args.Intrinsic, "section": section}), nil, nil
+	return nil, &IntrinsicSpecOutput{Intrinsic: args.Intrinsic, Section: section}, nil
 }
 
-func SearchSpec(ctx context.Context, req *mcp.CallToolRequest, args SearchSpecParams) (*mcp.CallToolResult, any, error) {
+func SearchSpec(ctx context.Context, req *mcp.CallToolRequest, args SearchSpecParams) (*mcp.CallToolResult, *SearchOutput, error) {
 	s, err := getSpecProvider()
 	if err != nil {
 		return nil, nil, err
@@ -73,19 +93,19 @@ func SearchSpec(ctx context.Context, req *mcp.CallToolRequest, args SearchSpecPa
 	sort.Strings(results)
 	page, pageSize := normalizePage(args.Page, args.PageSize)
 	items, remaining, total := paginateStrings(results, page, pageSize, args.Max)
-	res := map[string]any{
-		"query":     args.Query,
-		"page":      page,
-		"page_size": pageSize,
-		"returned":  len(items),
-		"remaining": remaining,
-		"total":     total,
-		"results":   items,
+	res := &SearchOutput{
+		Query:     args.Query,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Results:   items,
 	}
-	return utils.RespondWith(res), nil, nil
+	return nil, res, nil
 }
 
-func SearchSections(ctx context.Context, req *mcp.CallToolRequest, args SearchSectionsParams) (*mcp.CallToolResult, any, error) {
+func SearchSections(ctx context.Context, req *mcp.CallToolRequest, args SearchSectionsParams) (*mcp.CallToolResult, *SectionSearchOutput, error) {
 	s, err := getSpecProvider()
 	if err != nil {
 		return nil, nil, err
@@ -97,35 +117,35 @@ func SearchSections(ctx context.Context, req *mcp.CallToolRequest, args SearchSe
 	sort.Strings(results)
 	page, pageSize := normalizePage(args.Page, args.PageSize)
 	items, remaining, total := paginateStrings(results, page, pageSize, args.Max)
-	res := map[string]any{
-		"query":     args.Query,
-		"page":      page,
-		"page_size": pageSize,
-		"returned":  len(items),
-		"remaining": remaining,
-		"total":     total,
-		"sections":  items,
+	res := &SectionSearchOutput{
+		Query:     args.Query,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Sections:  items,
 	}
-	return utils.RespondWith(res), nil, nil
+	return nil, res, nil
 }
 
 func AddSpecTools(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetSpec",
 		Description: "Get the content of a specification file or section",
 	}, GetSpec)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "SpecForIntrinsic",
 		Description: "Lookup the spec section for a given intrinsic",
 	}, SpecForIntrinsic)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "SearchSpec",
 		Description: "Search the specification content for a query (paginated)",
 	}, SearchSpec)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "SearchSections",
 		Description: "Search spec section titles/ids for a query (paginated)",
 	}, SearchSections)
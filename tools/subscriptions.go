@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/Sharktheone/mcp262/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// subscriptions maps the resolved test or directory path of subscribed results:// resources to
+// the URIs they were subscribed with, the SDK matches sessions by the exact URI.
+var subscriptions = struct {
+	mu      sync.RWMutex
+	tests   map[string]map[string]bool
+	dirs    map[string]map[string]bool
+	servers []*mcp.Server
+}{
+	tests: make(map[string]map[string]bool),
+	dirs:  make(map[string]map[string]bool),
+}
+
+// Subscribe is the mcp.ServerOptions SubscribeHandler, only results:// resources can be subscribed to.
+// A directory subscription is notified for changes anywhere below the directory.
+func Subscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	uri := req.Params.URI
+
+	var set map[string]map[string]bool
+	var key string
+
+	if p, err := resourcePath(uri, RESULTS_URI); err == nil {
+		set, key = subscriptions.tests, utils.ResolvePath(p)
+	} else if p, err := resourcePath(uri, RESULTS_DIR_URI); err == nil {
+		set, key = subscriptions.dirs, utils.ResolvePath(strings.TrimSuffix(p, "/"))
+	} else {
+		return errors.New("only " + RESULTS_URI + " and " + RESULTS_DIR_URI + " resources can be subscribed to")
+	}
+
+	subscriptions.mu.Lock()
+	defer subscriptions.mu.Unlock()
+
+	if set[key] == nil {
+		set[key] = make(map[string]bool)
+	}
+
+	set[key][uri] = true
+
+	return nil
+}
+
+// Unsubscribe is the mcp.ServerOptions UnsubscribeHandler. URIs stay registered, other sessions may still
+// be subscribed to them and the SDK only notifies sessions that are.
+func Unsubscribe(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	return nil
+}
+
+// NotifyStatusChanges sends resources/updated notifications for the changed tests and their directories.
+// It is a testtree.ChangeListener.
+func NotifyStatusChanges(paths []string) {
+	subscriptions.mu.RLock()
+
+	if len(subscriptions.tests) == 0 && len(subscriptions.dirs) == 0 {
+		subscriptions.mu.RUnlock()
+		return
+	}
+
+	uris := make(map[string]bool)
+	for _, p := range paths {
+		for uri := range subscriptions.tests[p] {
+			uris[uri] = true
+		}
+
+		for dir := path.Dir(p); ; dir = path.Dir(dir) {
+			if dir == "." || dir == "/" {
+				dir = ""
+			}
+
+			for uri := range subscriptions.dirs[dir] {
+				uris[uri] = true
+			}
+
+			if dir == "" {
+				break
+			}
+		}
+	}
+
+	servers := subscriptions.servers
+	subscriptions.mu.RUnlock()
+
+	for uri := range uris {
+		for _, s := range servers {
+			_ = s.ResourceUpdated(context.Background(), &mcp.ResourceUpdatedNotificationParams{URI: uri})
+		}
+	}
+}
+
+func addSubscriptionServer(server *mcp.Server) {
+	subscriptions.mu.Lock()
+	defer subscriptions.mu.Unlock()
+
+	subscriptions.servers = append(subscriptions.servers, server)
+}
@@ -5,14 +5,18 @@ import (
 	"errors"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/Sharktheone/mcp262/provider"
+	"github.com/Sharktheone/mcp262/runner/panics"
 	"github.com/Sharktheone/mcp262/utils"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 const DefaultPageSize = 20
 
+const resultsSource = "(results from last CI run, overlaid with local runs in live mode)"
+
 type NumTestsRecursiveParams struct {
 	Path string `json:"path" jsonschema:"Path of the directory, starting from /test262/test/{built-ins,language,...}/..., /test/{built-ins,language,...}/... or just /{built-ins,language,...}/..."`
 }
@@ -91,18 +95,104 @@ type SearchTestInDirParams struct {
 	Max      int    `json:"max" jsonschema:"Optional global maximum number of items to include across all pages; 0 means no limit"`
 }
 
+type NumTestsTotalOutput struct {
+	NumTests int `json:"num_tests" jsonschema:"Total number of tests"`
+}
+
+type NumTestsInDirOutput struct {
+	NumTests  int    `json:"num_tests" jsonschema:"Number of tests in the directory"`
+	Path      string `json:"path" jsonschema:"Directory path as requested"`
+	Recursive bool   `json:"recursive" jsonschema:"Whether tests in subdirectories are counted"`
+}
+
+type TestListOutput struct {
+	Path      string   `json:"path" jsonschema:"Directory path as requested"`
+	Status    string   `json:"status,omitempty" jsonschema:"Status the tests are filtered by"`
+	Page      int      `json:"page" jsonschema:"Page number starting from 1"`
+	PageSize  int      `json:"page_size" jsonschema:"Items per page"`
+	Returned  int      `json:"returned" jsonschema:"Number of items on this page"`
+	Remaining int      `json:"remaining" jsonschema:"Number of items after this page (limited by max)"`
+	Total     int      `json:"total" jsonschema:"Number of items across all pages"`
+	Tests     []string `json:"tests" jsonschema:"Test paths"`
+}
+
+type TestStatusOutput struct {
+	TestPath string `json:"test_path" jsonschema:"Test path as requested"`
+	Status   string `json:"status" jsonschema:"Status of the test (PASS, FAIL, SKIP, TIMEOUT, CRASH, PARSE_ERROR, NOT_IMPLEMENTED, RUNNER_ERROR)"`
+	Source   string `json:"source,omitempty" jsonschema:"Where the status comes from (ci or local)"`
+	Produced string `json:"produced,omitempty" jsonschema:"When the status was produced (RFC 3339)"`
+}
+
+type TestStatusesOutput struct {
+	Path      string            `json:"path" jsonschema:"Directory path as requested"`
+	Page      int               `json:"page" jsonschema:"Page number starting from 1"`
+	PageSize  int               `json:"page_size" jsonschema:"Items per page"`
+	Returned  int               `json:"returned" jsonschema:"Number of items on this page"`
+	Remaining int               `json:"remaining" jsonschema:"Number of items after this page (limited by max)"`
+	Total     int               `json:"total" jsonschema:"Number of items across all pages"`
+	Statuses  map[string]string `json:"statuses" jsonschema:"Status by test path"`
+}
+
+type TestOutput struct {
+	TestPath string           `json:"test_path" jsonschema:"Test path as requested"`
+	Output   string           `json:"output" jsonschema:"Output of the test"`
+	Status   string           `json:"status" jsonschema:"Status of the test"`
+	Panic    *panics.Location `json:"panic,omitempty" jsonschema:"Engine panic location parsed from the output"`
+}
+
+type SearchOutput struct {
+	Dir       string   `json:"dir,omitempty" jsonschema:"Directory the search is restricted to"`
+	Query     string   `json:"query" jsonschema:"Search query"`
+	Page      int      `json:"page" jsonschema:"Page number starting from 1"`
+	PageSize  int      `json:"page_size" jsonschema:"Items per page"`
+	Returned  int      `json:"returned" jsonschema:"Number of items on this page"`
+	Remaining int      `json:"remaining" jsonschema:"Number of items after this page (limited by max)"`
+	Total     int      `json:"total" jsonschema:"Number of items across all pages"`
+	Results   []string `json:"results" jsonschema:"Matching paths"`
+}
+
+type TestSearchOutput struct {
+	Dir       string   `json:"dir,omitempty" jsonschema:"Directory the search is restricted to"`
+	Query     string   `json:"query" jsonschema:"Search query"`
+	Page      int      `json:"page" jsonschema:"Page number starting from 1"`
+	PageSize  int      `json:"page_size" jsonschema:"Items per page"`
+	Returned  int      `json:"returned" jsonschema:"Number of items on this page"`
+	Remaining int      `json:"remaining" jsonschema:"Number of items after this page (limited by max)"`
+	Total     int      `json:"total" jsonschema:"Number of items across all pages"`
+	Tests     []string `json:"tests" jsonschema:"Matching test paths"`
+}
+
 // Tool handlers
 
-func NumTestsTotal(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+func NumTestsTotal(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, *NumTestsTotalOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
 	}
 	n := prov.NumTests()
-	return utils.RespondWith(map[string]any{"num_tests": n}), nil, nil
+	return nil, &NumTestsTotalOutput{NumTests: n}, nil
+}
+
+func RefreshResults(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, *provider.RefreshResult, error) {
+	prov, err := getProvider()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rp, ok := prov.(provider.RefreshableProvider)
+	if !ok {
+		return nil, nil, errors.New("test provider does not support refreshing")
+	}
+
+	res, err := rp.Refresh()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nil, &res, nil
 }
 
-func NumTestsInDir(ctx context.Context, req *mcp.CallToolRequest, args NumTestsRecursiveParams) (*mcp.CallToolResult, any, error) {
+func NumTestsInDir(ctx context.Context, req *mcp.CallToolRequest, args NumTestsRecursiveParams) (*mcp.CallToolResult, *NumTestsInDirOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -112,11 +202,10 @@ func NumTestsInDir(ctx context.Context, req *mcp.CallToolRequest, args NumTestsR
 	if err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"num_tests": n, "path": args.Path, "recursive": true}), nil, nil
-
+	return nil, &NumTestsInDirOutput{NumTests: n, Path: args.Path}, nil
 }
 
-func NumTestsInDirRecursive(ctx context.Context, req *mcp.CallToolRequest, args NumTestsRecursiveParams) (*mcp.CallToolResult, any, error) {
+func NumTestsInDirRecursive(ctx context.Context, req *mcp.CallToolRequest, args NumTestsRecursiveParams) (*mcp.CallToolResult, *NumTestsInDirOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -126,10 +215,10 @@ func NumTestsInDirRecursive(ctx context.Context, req *mcp.CallToolRequest, args
 	if err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"num_tests": n, "path": args.Path, "recursive": true}), nil, nil
+	return nil, &NumTestsInDirOutput{NumTests: n, Path: args.Path, Recursive: true}, nil
 }
 
-func GetTestsInDir(ctx context.Context, req *mcp.CallToolRequest, args GetTestsInDirParams) (*mcp.CallToolResult, any, error) {
+func GetTestsInDir(ctx context.Context, req *mcp.CallToolRequest, args GetTestsInDirParams) (*mcp.CallToolResult, *TestListOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -142,19 +231,19 @@ func GetTestsInDir(ctx context.Context, req *mcp.CallToolRequest, args GetTestsI
 	sort.Strings(tests)
 	page, pageSize := normalizePage(args.Page, args.PageSize)
 	items, remaining, total := paginateStrings(tests, page, pageSize, args.Max)
-	res := map[string]any{
-		"path":      args.Path,
-		"page":      page,
-		"page_size": pageSize,
-		"returned":  len(items),
-		"remaining": remaining,
-		"total":     total,
-		"tests":     items,
+	res := &TestListOutput{
+		Path:      args.Path,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Tests:     items,
 	}
-	return utils.RespondWith(res), nil, nil
+	return nil, res, nil
 }
 
-func GetTestsInDirRec(ctx context.Context, req *mcp.CallToolRequest, args GetTestsInDirParams) (*mcp.CallToolResult, any, error) {
+func GetTestsInDirRec(ctx context.Context, req *mcp.CallToolRequest, args GetTestsInDirParams) (*mcp.CallToolResult, *TestListOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -167,19 +256,19 @@ func GetTestsInDirRec(ctx context.Context, req *mcp.CallToolRequest, args GetTes
 	sort.Strings(tests)
 	page, pageSize := normalizePage(args.Page, args.PageSize)
 	items, remaining, total := paginateStrings(tests, page, pageSize, args.Max)
-	res := map[string]any{
-		"path":      args.Path,
-		"page":      page,
-		"page_size": pageSize,
-		"returned":  len(items),
-		"remaining": remaining,
-		"total":     total,
-		"tests":     items,
+	res := &TestListOutput{
+		Path:      args.Path,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Tests:     items,
 	}
-	return utils.RespondWith(res), nil, nil
+	return nil, res, nil
 }
 
-func GetTestStatus(ctx context.Context, req *mcp.CallToolRequest, args GetTestStatusParams) (*mcp.CallToolResult, any, error) {
+func GetTestStatus(ctx context.Context, req *mcp.CallToolRequest, args GetTestStatusParams) (*mcp.CallToolResult, *TestStatusOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -189,10 +278,19 @@ func GetTestStatus(ctx context.Context, req *mcp.CallToolRequest, args GetTestSt
 	if err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"test_path": args.TestPath, "status": status}), nil, nil
+	res := &TestStatusOutput{TestPath: args.TestPath, Status: status}
+	if sp, ok := prov.(provider.TestSourceProvider); ok {
+		if source, produced, err := sp.GetTestSource(p); err == nil {
+			res.Source = source
+			if !produced.IsZero() {
+				res.Produced = produced.Format(time.RFC3339)
+			}
+		}
+	}
+	return nil, res, nil
 }
 
-func GetTestStatusesInDir(ctx context.Context, req *mcp.CallToolRequest, args GetStatusesInDirParams) (*mcp.CallToolResult, any, error) {
+func GetTestStatusesInDir(ctx context.Context, req *mcp.CallToolRequest, args GetStatusesInDirParams) (*mcp.CallToolResult, *TestStatusesOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -214,19 +312,19 @@ func GetTestStatusesInDir(ctx context.Context, req *mcp.CallToolRequest, args Ge
 	for _, k := range items {
 		paged[k] = statuses[k]
 	}
-	res := map[string]any{
-		"path":      args.Path,
-		"page":      page,
-		"page_size": pageSize,
-		"returned":  len(items),
-		"remaining": remaining,
-		"total":     total,
-		"statuses":  paged,
+	res := &TestStatusesOutput{
+		Path:      args.Path,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Statuses:  paged,
 	}
-	return utils.RespondWith(res), nil, nil
+	return nil, res, nil
 }
 
-func GetTestStatusesInDirRec(ctx context.Context, req *mcp.CallToolRequest, args GetStatusesInDirParams) (*mcp.CallToolResult, any, error) {
+func GetTestStatusesInDirRec(ctx context.Context, req *mcp.CallToolRequest, args GetStatusesInDirParams) (*mcp.CallToolResult, *TestStatusesOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -248,19 +346,19 @@ func GetTestStatusesInDirRec(ctx context.Context, req *mcp.CallToolRequest, args
 	for _, k := range items {
 		paged[k] = statuses[k]
 	}
-	res := map[string]any{
-		"path":      args.Path,
-		"page":      page,
-		"page_size": pageSize,
-		"returned":  len(items),
-		"remaining": remaining,
-		"total":     total,
-		"statuses":  paged,
+	res := &TestStatusesOutput{
+		Path:      args.Path,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Statuses:  paged,
 	}
-	return utils.RespondWith(res), nil, nil
+	return nil, res, nil
 }
 
-func GetTestsWithStatusInDir(ctx context.Context, req *mcp.CallToolRequest, args GetTestsWithStatusInDirParams) (*mcp.CallToolResult, any, error) {
+func GetTestsWithStatusInDir(ctx context.Context, req *mcp.CallToolRequest, args GetTestsWithStatusInDirParams) (*mcp.CallToolResult, *TestListOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -273,20 +371,20 @@ func GetTestsWithStatusInDir(ctx context.Context, req *mcp.CallToolRequest, args
 	sort.Strings(tests)
 	page, pageSize := normalizePage(args.Page, args.PageSize)
 	items, remaining, total := paginateStrings(tests, page, pageSize, args.Max)
-	res := map[string]any{
-		"path":      args.Path,
-		"status":    args.Status,
-		"page":      page,
-		"page_size": pageSize,
-		"returned":  len(items),
-		"remaining": remaining,
-		"total":     total,
-		"tests":     items,
+	res := &TestListOutput{
+		Path:      args.Path,
+		Status:    args.Status,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Tests:     items,
 	}
-	return utils.RespondWith(res), nil, nil
+	return nil, res, nil
 }
 
-func GetTestsWithStatusInDirRec(ctx context.Context, req *mcp.CallToolRequest, args GetTestsWithStatusInDirParams) (*mcp.CallToolResult, any, error) {
+func GetTestsWithStatusInDirRec(ctx context.Context, req *mcp.CallToolRequest, args GetTestsWithStatusInDirParams) (*mcp.CallToolResult, *TestListOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -299,20 +397,20 @@ func GetTestsWithStatusInDirRec(ctx context.Context, req *mcp.CallToolRequest, a
 	sort.Strings(tests)
 	page, pageSize := normalizePage(args.Page, args.PageSize)
 	items, remaining, total := paginateStrings(tests, page, pageSize, args.Max)
-	res := map[string]any{
-		"path":      args.Path,
-		"status":    args.Status,
-		"page":      page,
-		"page_size": pageSize,
-		"returned":  len(items),
-		"remaining": remaining,
-		"total":     total,
-		"tests":     items,
+	res := &TestListOutput{
+		Path:      args.Path,
+		Status:    args.Status,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Tests:     items,
 	}
-	return utils.RespondWith(res), nil, nil
+	return nil, res, nil
 }
 
-func GetFailedTestsInDir(ctx context.Context, req *mcp.CallToolRequest, args GetFailedTestsInDirParams) (*mcp.CallToolResult, any, error) {
+func GetFailedTestsInDir(ctx context.Context, req *mcp.CallToolRequest, args GetFailedTestsInDirParams) (*mcp.CallToolResult, *TestListOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -325,19 +423,19 @@ func GetFailedTestsInDir(ctx context.Context, req *mcp.CallToolRequest, args Get
 	sort.Strings(tests)
 	page, pageSize := normalizePage(args.Page, args.PageSize)
 	items, remaining, total := paginateStrings(tests, page, pageSize, args.Max)
-	res := map[string]any{
-		"path":      args.Path,
-		"page":      page,
-		"page_size": pageSize,
-		"returned":  len(items),
-		"remaining": remaining,
-		"total":     total,
-		"tests":     items,
+	res := &TestListOutput{
+		Path:      args.Path,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Tests:     items,
 	}
-	return utils.RespondWith(res), nil, nil
+	return nil, res, nil
 }
 
-func GetFailedTestsInDirRec(ctx context.Context, req *mcp.CallToolRequest, args GetFailedTestsInDirParams) (*mcp.CallToolResult, any, error) {
+func GetFailedTestsInDirRec(ctx context.Context, req *mcp.CallToolRequest, args GetFailedTestsInDirParams) (*mcp.CallToolResult, *TestListOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -350,19 +448,19 @@ func GetFailedTestsInDirRec(ctx context.Context, req *mcp.CallToolRequest, args
 	sort.Strings(tests)
 	page, pageSize := normalizePage(args.Page, args.PageSize)
 	items, remaining, total := paginateStrings(tests, page, pageSize, args.Max)
-	res := map[string]any{
-		"path":      args.Path,
-		"page":      page,
-		"page_size": pageSize,
-		"returned":  len(items),
-		"remaining": remaining,
-		"total":     total,
-		"tests":     items,
+	res := &TestListOutput{
+		Path:      args.Path,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Tests:     items,
 	}
-	return utils.RespondWith(res), nil, nil
+	return nil, res, nil
 }
 
-func GetTestOutput(ctx context.Context, req *mcp.CallToolRequest, args GetTestOutputParams) (*mcp.CallToolResult, any, error) {
+func GetTestOutput(ctx context.Context, req *mcp.CallToolRequest, args GetTestOutputParams) (*mcp.CallToolResult, *TestOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -377,10 +475,11 @@ func GetTestOutput(ctx context.Context, req *mcp.CallToolRequest, args GetTestOu
 	if err != nil {
 		return nil, nil, err
 	}
-	return utils.RespondWith(map[string]any{"test_path": args.TestPath, "output": out, "status": status}), nil, nil
+	res := &TestOutput{TestPath: args.TestPath, Output: out, Status: status, Panic: panics.Parse(out)}
+	return nil, res, nil
 }
 
-func SearchDir(ctx context.Context, req *mcp.CallToolRequest, args SearchDirParams) (*mcp.CallToolResult, any, error) {
+func SearchDir(ctx context.Context, req *mcp.CallToolRequest, args SearchDirParams) (*mcp.CallToolResult, *SearchOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -392,19 +491,19 @@ func SearchDir(ctx context.Context, req *mcp.CallToolRequest, args SearchDirPara
 	sort.Strings(results)
 	page, pageSize := normalizePage(args.Page, args.PageSize)
 	items, remaining, total := paginateStrings(results, page, pageSize, args.Max)
-	res := map[string]any{
-		"query":     args.Query,
-		"page":      page,
-		"page_size": pageSize,
-		"returned":  len(items),
-		"remaining": remaining,
-		"total":     total,
-		"results":   items,
+	res := &SearchOutput{
+		Query:     args.Query,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Results:   items,
 	}
-	return utils.RespondWith(res), nil, nil
+	return nil, res, nil
 }
 
-func SearchDirIn(ctx context.Context, req *mcp.CallToolRequest, args SearchDirInParams) (*mcp.CallToolResult, any, error) {
+func SearchDirIn(ctx context.Context, req *mcp.CallToolRequest, args SearchDirInParams) (*mcp.CallToolResult, *SearchOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -417,20 +516,20 @@ func SearchDirIn(ctx context.Context, req *mcp.CallToolRequest, args SearchDirIn
 	sort.Strings(results)
 	page, pageSize := normalizePage(args.Page, args.PageSize)
 	items, remaining, total := paginateStrings(results, page, pageSize, args.Max)
-	res := map[string]any{
-		"dir":       args.Dir,
-		"query":     args.Query,
-		"page":      page,
-		"page_size": pageSize,
-		"returned":  len(items),
-		"remaining": remaining,
-		"total":     total,
-		"results":   items,
+	res := &SearchOutput{
+		Dir:       args.Dir,
+		Query:     args.Query,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Results:   items,
 	}
-	return utils.RespondWith(res), nil, nil
+	return nil, res, nil
 }
 
-func SearchTest(ctx context.Context, req *mcp.CallToolRequest, args SearchTestParams) (*mcp.CallToolResult, any, error) {
+func SearchTest(ctx context.Context, req *mcp.CallToolRequest, args SearchTestParams) (*mcp.CallToolResult, *TestSearchOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -442,19 +541,19 @@ func SearchTest(ctx context.Context, req *mcp.CallToolRequest, args SearchTestPa
 	sort.Strings(results)
 	page, pageSize := normalizePage(args.Page, args.PageSize)
 	items, remaining, total := paginateStrings(results, page, pageSize, args.Max)
-	res := map[string]any{
-		"query":     args.Query,
-		"page":      page,
-		"page_size": pageSize,
-		"returned":  len(items),
-		"remaining": remaining,
-		"total":     total,
-		"tests":     items,
+	res := &TestSearchOutput{
+		Query:     args.Query,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Tests:     items,
 	}
-	return utils.RespondWith(res), nil, nil
+	return nil, res, nil
 }
 
-func SearchTestInDir(ctx context.Context, req *mcp.CallToolRequest, args SearchTestInDirParams) (*mcp.CallToolResult, any, error) {
+func SearchTestInDir(ctx context.Context, req *mcp.CallToolRequest, args SearchTestInDirParams) (*mcp.CallToolResult, *TestSearchOutput, error) {
 	prov, err := getProvider()
 	if err != nil {
 		return nil, nil, err
@@ -467,109 +566,114 @@ func SearchTestInDir(ctx context.Context, req *mcp.CallToolRequest, args SearchT
 	sort.Strings(results)
 	page, pageSize := normalizePage(args.Page, args.PageSize)
 	items, remaining, total := paginateStrings(results, page, pageSize, args.Max)
-	res := map[string]any{
-		"dir":       args.Dir,
-		"query":     args.Query,
-		"page":      page,
-		"page_size": pageSize,
-		"returned":  len(items),
-		"remaining": remaining,
-		"total":     total,
-		"tests":     items,
+	res := &TestSearchOutput{
+		Dir:       args.Dir,
+		Query:     args.Query,
+		Page:      page,
+		PageSize:  pageSize,
+		Returned:  len(items),
+		Remaining: remaining,
+		Total:     total,
+		Tests:     items,
 	}
-	return utils.RespondWith(res), nil, nil
+	return nil, res, nil
 }
 
 func AddTools(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "NumTestsTotal",
-		Description: "Get the total number of tests (results from last CI run)",
+		Description: "Get the total number of tests " + resultsSource,
 	}, NumTestsTotal)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "NumTestsInDir",
-		Description: "Get the number of tests in a directory (results from last CI run)",
+		Description: "Get the number of tests in a directory " + resultsSource,
 	}, NumTestsInDir)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "NumTestsInDirRecursive",
-		Description: "Get the number of tests in a directory recursively (results from last CI run)",
+		Description: "Get the number of tests in a directory recursively " + resultsSource,
 	}, NumTestsInDirRecursive)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetTestsInDirRecursive",
-		Description: "List tests in a directory recursively (paginated) (results from last CI run)",
+		Description: "List tests in a directory recursively (paginated) " + resultsSource,
 	}, GetTestsInDirRec)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetTestsInDir",
-		Description: "List tests in a directory (paginated) (results from last CI run)",
+		Description: "List tests in a directory (paginated) " + resultsSource,
 	}, GetTestsInDir)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetTestStatus",
-		Description: "Get the status of a single test (results from last CI run)",
+		Description: "Get the status of a single test " + resultsSource,
 	}, GetTestStatus)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetTestStatusesInDirRecursive",
-		Description: "List statuses for tests in a directory recursively (paginated) (results from last CI run)",
+		Description: "List statuses for tests in a directory recursively (paginated) " + resultsSource,
 	}, GetTestStatusesInDirRec)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetTestStatusesInDir",
-		Description: "List statuses for tests in a directory (paginated) (results from last CI run)",
+		Description: "List statuses for tests in a directory (paginated) " + resultsSource,
 	}, GetTestStatusesInDir)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetTestsWithStatusInDirRecursive",
-		Description: "List tests with a specific status in a directory recursively (paginated) (results from last CI run)",
+		Description: "List tests with a specific status in a directory recursively (paginated) " + resultsSource,
 	}, GetTestsWithStatusInDirRec)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetTestsWithStatusInDir",
-		Description: "List tests with a specific status in a directory (paginated) (results from last CI run)",
+		Description: "List tests with a specific status in a directory (paginated) " + resultsSource,
 	}, GetTestsWithStatusInDir)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetFailedTestsInDirRecursive",
-		Description: "List failed tests in a directory recursively (paginated) (results from last CI run)",
+		Description: "List failed tests in a directory recursively (paginated) " + resultsSource,
 	}, GetFailedTestsInDirRec)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetFailedTestsInDir",
-		Description: "List failed tests in a directory (paginated) (results from last CI run)",
+		Description: "List failed tests in a directory (paginated) " + resultsSource,
 	}, GetFailedTestsInDir)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetTestsWithStatusInDirRecursive",
-		Description: "List tests with a specific status in a directory recursively (paginated) (results from last CI run)",
+		Description: "List tests with a specific status in a directory recursively (paginated) " + resultsSource,
 	}, GetTestsWithStatusInDirRec)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "GetTestOutput",
-		Description: "Get the output of a single test (results from last CI run)",
+		Description: "Get the output of a single test " + resultsSource,
 	}, GetTestOutput)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "SearchDir",
-		Description: "Search repository paths by query (paginated) (results from last CI run)",
+		Description: "Search repository paths by query (paginated) " + resultsSource,
 	}, SearchDir)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "SearchDirIn",
-		Description: "Search repository paths within a directory by query (paginated) (results from last CI run)",
+		Description: "Search repository paths within a directory by query (paginated) " + resultsSource,
 	}, SearchDirIn)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "SearchTest",
-		Description: "Search tests by query (paginated) (results from last CI run)",
+		Description: "Search tests by query (paginated) " + resultsSource,
 	}, SearchTest)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "SearchTestInDir",
-		Description: "Search tests within a directory by query (paginated) (results from last CI run)",
+		Description: "Search tests within a directory by query (paginated) " + resultsSource,
 	}, SearchTestInDir)
+
+	addTool(server, &mcp.Tool{
+		Name:        "RefreshResults",
+		Description: "Reload the CI results without restarting the server and report how many tests changed status (FROM -> TO); results of local runs are kept",
+	}, RefreshResults)
 }
 
 func getProvider() (provider.TestProvider, error) {
@@ -590,6 +694,10 @@ func normalizePage(page, pageSize int) (int, int) {
 }
 
 func paginateStrings(items []string, page, pageSize, max int) ([]string, int, int) {
+	return paginate(items, page, pageSize, max)
+}
+
+func paginate[T any](items []T, page, pageSize, max int) ([]T, int, int) {
 	total := len(items)
 	limit := total
 	if max > 0 && max < limit {
@@ -604,6 +712,9 @@ func paginateStrings(items []string, page, pageSize, max int) ([]string, int, in
 		end = limit
 	}
 	slice := items[start:end]
+	if slice == nil {
+		slice = make([]T, 0)
+	}
 	remaining := limit - end
 	return slice, remaining, total
 }
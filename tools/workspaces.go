@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Sharktheone/mcp262/provider"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type UseWorkspaceParams struct {
+	Name string `json:"name" jsonschema:"Name of the shared workspace to switch to (created if missing); empty to switch back to the private workspace of this session"`
+}
+
+type DeleteWorkspaceParams struct {
+	Name string `json:"name" jsonschema:"Name of the shared workspace to delete"`
+}
+
+type WorkspaceOutput struct {
+	Workspace     string `json:"workspace" jsonschema:"Name of the workspace"`
+	EditedTests   int    `json:"edited_tests" jsonschema:"Number of edited tests in the workspace"`
+	EditedHarness int    `json:"edited_harness" jsonschema:"Number of edited harness files in the workspace"`
+}
+
+type ListWorkspacesOutput struct {
+	Current    string                   `json:"current" jsonschema:"Workspace used by this session"`
+	Workspaces []provider.WorkspaceInfo `json:"workspaces" jsonschema:"Shared workspaces first, then the private workspaces of sessions"`
+}
+
+type DeleteWorkspaceOutput struct {
+	Name    string `json:"name" jsonschema:"Name as requested"`
+	Deleted bool   `json:"deleted" jsonschema:"Whether the workspace was deleted"`
+}
+
+func UseWorkspace(ctx context.Context, req *mcp.CallToolRequest, args UseWorkspaceParams) (*mcp.CallToolResult, *WorkspaceOutput, error) {
+	wp, err := getWorkspaceProvider()
+	if err != nil {
+		return nil, nil, err
+	}
+	w, err := wp.UseWorkspace(req.Session, args.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, workspaceOutput(w), nil
+}
+
+func ListWorkspaces(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, *ListWorkspacesOutput, error) {
+	wp, err := getWorkspaceProvider()
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &ListWorkspacesOutput{
+		Current:    wp.SessionWorkspace(req.Session).Name(),
+		Workspaces: wp.Workspaces(),
+	}, nil
+}
+
+func DeleteWorkspace(ctx context.Context, req *mcp.CallToolRequest, args DeleteWorkspaceParams) (*mcp.CallToolResult, *DeleteWorkspaceOutput, error) {
+	wp, err := getWorkspaceProvider()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := wp.DeleteWorkspace(args.Name); err != nil {
+		return nil, nil, err
+	}
+	return nil, &DeleteWorkspaceOutput{Name: args.Name, Deleted: true}, nil
+}
+
+func AddWorkspaceTools(server *mcp.Server) {
+	addTool(server, &mcp.Tool{
+		Name:        "UseWorkspace",
+		Description: "Switch this session to a shared edit workspace, so edits are visible to (and runs see the edits of) every session using it",
+	}, UseWorkspace)
+
+	addTool(server, &mcp.Tool{
+		Name:        "ListWorkspaces",
+		Description: "List the edit workspaces with their number of sessions and edited files",
+	}, ListWorkspaces)
+
+	addTool(server, &mcp.Tool{
+		Name:        "DeleteWorkspace",
+		Description: "Delete a shared edit workspace and its edits, sessions using it switch back to their private workspace",
+	}, DeleteWorkspace)
+}
+
+// TrackSession is the mcp.ServerOptions InitializedHandler, it drops the private workspace of the session once it is closed.
+func TrackSession(ctx context.Context, req *mcp.InitializedRequest) {
+	wp, err := getWorkspaceProvider()
+	if err != nil {
+		return
+	}
+
+	session := req.Session
+	go func() {
+		_ = session.Wait()
+		wp.CloseSession(session)
+	}()
+}
+
+// sessionOverlay returns the edits of the workspace of the session, if edits are scoped to sessions.
+func sessionOverlay(session *mcp.ServerSession) provider.Overlay {
+	wp, err := getWorkspaceProvider()
+	if err != nil {
+		return provider.Overlay{}
+	}
+	return wp.SessionWorkspace(session).Overlay()
+}
+
+func workspaceOutput(w provider.EditWorkspace) *WorkspaceOutput {
+	o := w.Overlay()
+	return &WorkspaceOutput{
+		Workspace:     w.Name(),
+		EditedTests:   len(o.Tests),
+		EditedHarness: len(o.Harness),
+	}
+}
+
+func getWorkspaceProvider() (provider.WorkspaceProvider, error) {
+	wp, ok := provider.CodeProvider.(provider.WorkspaceProvider)
+	if !ok {
+		return nil, errors.New("code provider does not support workspaces")
+	}
+	return wp, nil
+}
@@ -1,22 +1,10 @@
 package utils
 
 import (
-	"encoding/json"
 	"log"
 	"strings"
-
-	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func RespondWith(res any) *mcp.CallToolResult {
-	b, _ := json.Marshal(res)
-
-	return &mcp.CallToolResult{
-		StructuredContent: json.RawMessage(b),
-		Content:           []mcp.Content{&mcp.TextContent{Text: string(b)}},
-	}
-}
-
 func ResolvePath(path string) string {
 	log.Println(path)
 	path = strings.TrimPrefix(path, "/")